@@ -1,20 +1,32 @@
 import {useState, useEffect} from "react";
+import Button from "react-bootstrap/Button";
 import axiosClient from '../../api/axiosConfig';
 import Movies from '../movies/Movies'
 
+const pageSize = 24;
+
 const Home =() => {
     const [movies, setMovies] = useState([]);
     const [loading, setLoading] = useState([false]);
+    const [loadingMore, setLoadingMore] = useState(false);
+    const [nextCursor, setNextCursor] = useState();
     const [message, setMessage] = useState();
 
+    // fetches one page; the next page is only requested when the user asks
+    const fetchPage = async (cursor) => {
+        const response = await axiosClient.get('/movies', {params: {limit: pageSize, cursor}});
+        setNextCursor(response.data.next_cursor);
+        return response.data.movies;
+    }
+
     useEffect(() => {
         const fetchMovies = async () => {
             setLoading(true);
             setMessage("");
             try{
-                const response = await axiosClient.get('/movies');
-                setMovies(response.data);
-                if (response.data.length === 0){
+                const firstPage = await fetchPage();
+                setMovies(firstPage);
+                if (firstPage.length === 0){
                     setMessage('There are currently no movies available')
                 }
             }catch(error){
@@ -27,16 +39,37 @@ const Home =() => {
         fetchMovies();
     }, [])
 
+    const loadMore = async () => {
+        setLoadingMore(true);
+        try{
+            const page = await fetchPage(nextCursor);
+            setMovies((current) => current.concat(page));
+        }catch(error){
+            console.error('Error fetching more movies:', error)
+        }finally{
+            setLoadingMore(false)
+        }
+    }
+
     return (
         <>
             {loading ? (
                 <h2>Loading....</h2>
             ):(
-                <Movies movies ={movies} message ={message}></Movies>
+                <>
+                    <Movies movies ={movies} message ={message}></Movies>
+                    {nextCursor && (
+                        <div className="text-center my-4">
+                            <Button variant="outline-info" onClick={loadMore} disabled={loadingMore}>
+                                {loadingMore ? 'Loading...' : 'Load more'}
+                            </Button>
+                        </div>
+                    )}
+                </>
             )
             }
         </>
     )
- 
+
 }
-export default Home;
\ No newline at end of file
+export default Home;
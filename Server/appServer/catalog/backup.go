@@ -0,0 +1,370 @@
+package catalog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const FormatBSON = "bson"
+
+// how user credentials are written to a backup
+const (
+	SecretsExclude = "exclude"
+	SecretsRedact  = "redact"
+	SecretsInclude = "include"
+)
+
+const redactedValue = "[REDACTED]"
+
+// BackupCollections are the collections a backup holds by default.
+var BackupCollections = []string{"movies", "genres", "rankings", "prompt_templates", "users", "user_reviews", "watchlists", "watch_history", "watch_sessions"}
+
+// user fields that are never written unless secrets are included
+var userSecretFields = []string{"password", "token", "refresh_token"}
+
+type ExportOptions struct {
+	Collections []string
+	Format      string
+	Secrets     string
+}
+
+// archive header, the first record of every backup
+type backupManifest struct {
+	Version     int       `bson:"version"`
+	CreatedAt   time.Time `bson:"created_at"`
+	Format      string    `bson:"format"`
+	Secrets     string    `bson:"secrets"`
+	Collections []string  `bson:"collections"`
+}
+
+type backupRecord struct {
+	Collection string `bson:"collection"`
+	Document   bson.D `bson:"document"`
+}
+
+// Validate fills in the defaults of opts and checks its values, so callers
+// can reject a bad request before they start writing the archive.
+func (opts ExportOptions) Validate() (ExportOptions, error) {
+	if len(opts.Collections) == 0 {
+		opts.Collections = BackupCollections
+	}
+	if opts.Format == "" {
+		opts.Format = FormatJSONL
+	}
+	if opts.Secrets == "" {
+		opts.Secrets = SecretsExclude
+	}
+
+	for _, name := range opts.Collections {
+		if !slices.Contains(BackupCollections, name) {
+			return opts, fmt.Errorf("collection %q cannot be exported", name)
+		}
+	}
+	if opts.Format != FormatJSONL && opts.Format != FormatBSON {
+		return opts, fmt.Errorf("unsupported export format %q", opts.Format)
+	}
+	if opts.Secrets != SecretsExclude && opts.Secrets != SecretsRedact && opts.Secrets != SecretsInclude {
+		return opts, fmt.Errorf("unsupported secrets option %q", opts.Secrets)
+	}
+	return opts, nil
+}
+
+// ExportCollections streams the selected collections to w as a gzip
+// compressed archive. Each record is one document tagged with its
+// collection, encoded as extended JSON lines or as consecutive BSON
+// documents depending on opts.Format.
+func ExportCollections(ctx context.Context, client *mongo.Client, w io.Writer, opts ExportOptions) error {
+	opts, err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	writeRecord := recordWriter(gz, opts.Format)
+
+	manifest := backupManifest{
+		Version:     1,
+		CreatedAt:   time.Now(),
+		Format:      opts.Format,
+		Secrets:     opts.Secrets,
+		Collections: opts.Collections,
+	}
+	if err := writeRecord(manifest); err != nil {
+		return err
+	}
+
+	for _, name := range opts.Collections {
+		cursor, err := database.OpenCollection(name, client).Find(ctx, bson.D{})
+		if err != nil {
+			return fmt.Errorf("exporting %s: %w", name, err)
+		}
+
+		for cursor.Next(ctx) {
+			var document bson.D
+			if err := cursor.Decode(&document); err != nil {
+				cursor.Close(ctx)
+				return fmt.Errorf("exporting %s: %w", name, err)
+			}
+			if name == "users" {
+				document = scrubSecrets(document, opts.Secrets)
+			}
+			if err := writeRecord(backupRecord{Collection: name, Document: document}); err != nil {
+				cursor.Close(ctx)
+				return err
+			}
+		}
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return fmt.Errorf("exporting %s: %w", name, err)
+		}
+	}
+
+	return gz.Close()
+}
+
+// recordWriter returns a function that writes one archive record to w in
+// the given record format.
+func recordWriter(w io.Writer, format string) func(record any) error {
+	return func(record any) error {
+		if format == FormatBSON {
+			data, err := bson.Marshal(record)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(data)
+			return err
+		}
+
+		data, err := bson.MarshalExtJSON(record, true, false)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+}
+
+func scrubSecrets(document bson.D, secrets string) bson.D {
+	if secrets == SecretsInclude {
+		return document
+	}
+
+	scrubbed := make(bson.D, 0, len(document))
+	for _, elem := range document {
+		if slices.Contains(userSecretFields, elem.Key) {
+			if secrets == SecretsExclude {
+				continue
+			}
+			elem.Value = redactedValue
+		}
+		scrubbed = append(scrubbed, elem)
+	}
+	return scrubbed
+}
+
+// Number of documents restored per collection
+type RestoreReport map[string]int
+
+// unique fields a restored document is matched on, so an archive can be
+// loaded into a database whose documents have other _ids. Collections not
+// listed here are matched on _id.
+var restoreKeys = map[string][]string{
+	"movies":           {"imdb_id"},
+	"genres":           {"genre_id"},
+	"prompt_templates": {"name", "version"},
+	"users":            {"email"},
+	"user_reviews":     {"imdb_id", "user_id"},
+	"watchlists":       {"user_id"},
+	"watch_history":    {"user_id", "imdb_id"},
+}
+
+// ArchiveFormatFromFilename returns the record format of an archive named
+// like the ones ExportCatalog serves, for example backup.jsonl.gz.
+func ArchiveFormatFromFilename(filename string) string {
+	switch strings.ToLower(filepath.Ext(strings.TrimSuffix(filename, ".gz"))) {
+	case ".jsonl":
+		return FormatJSONL
+	case ".bson":
+		return FormatBSON
+	}
+	return ""
+}
+
+// RestoreCollections loads an archive written by ExportCollections in the
+// given record format, which must match the one in the archive manifest.
+// Documents replace the ones with the same natural key, or the same _id
+// for collections without one, so restoring the same archive twice leaves
+// the database unchanged. Users exported without their credentials keep
+// whatever credentials they already have.
+func RestoreCollections(ctx context.Context, client *mongo.Client, r io.Reader, format string) (RestoreReport, error) {
+	report := RestoreReport{}
+
+	var readRecord func(*bufio.Reader, any) error
+	switch format {
+	case FormatJSONL:
+		readRecord = readJSONRecord
+	case FormatBSON:
+		readRecord = readBSONRecord
+	default:
+		return report, fmt.Errorf("unsupported archive format %q", format)
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return report, fmt.Errorf("reading archive: %w", err)
+	}
+	defer gz.Close()
+
+	reader := bufio.NewReader(gz)
+
+	var manifest backupManifest
+	if err := readRecord(reader, &manifest); err != nil {
+		return report, fmt.Errorf("reading archive manifest: %w", err)
+	}
+	if manifest.Version != 1 {
+		return report, fmt.Errorf("unsupported archive version %d", manifest.Version)
+	}
+	if manifest.Format != format {
+		return report, fmt.Errorf("archive manifest says format %q, not %q", manifest.Format, format)
+	}
+
+	collections := map[string]*mongo.Collection{}
+	for {
+		var record backupRecord
+		err := readRecord(reader, &record)
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		if err != nil {
+			return report, fmt.Errorf("reading archive: %w", err)
+		}
+		if !slices.Contains(manifest.Collections, record.Collection) {
+			return report, fmt.Errorf("archive holds unexpected collection %q", record.Collection)
+		}
+
+		collection, ok := collections[record.Collection]
+		if !ok {
+			collection = database.OpenCollection(record.Collection, client)
+			collections[record.Collection] = collection
+		}
+
+		keepSecrets := record.Collection == "users" && manifest.Secrets != SecretsInclude
+		if err := restoreDocument(ctx, collection, record.Document, keepSecrets); err != nil {
+			return report, fmt.Errorf("restoring %s: %w", record.Collection, err)
+		}
+		report[record.Collection]++
+	}
+}
+
+// restoreDocument replaces the stored document matching document, or
+// inserts it with its archived _id when there is none. keepSecrets is set
+// for users restored from an archive without their credentials.
+func restoreDocument(ctx context.Context, collection *mongo.Collection, document bson.D, keepSecrets bool) error {
+	filter, id, fields := splitDocument(collection.Name(), document, keepSecrets)
+
+	if filter != nil {
+		var result *mongo.UpdateResult
+		var err error
+		if keepSecrets {
+			result, err = collection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: fields}})
+		} else {
+			result, err = collection.ReplaceOne(ctx, filter, fields)
+		}
+		if err != nil || result.MatchedCount > 0 {
+			return err
+		}
+	}
+
+	if id != nil {
+		fields = append(bson.D{{Key: "_id", Value: id}}, fields...)
+	}
+	_, err := collection.InsertOne(ctx, fields)
+	return err
+}
+
+// splitDocument separates an archived document of the named collection
+// into the filter that finds its stored copy, its _id and the fields to
+// write. The filter is nil when the document has neither a natural key
+// nor an _id.
+func splitDocument(collection string, document bson.D, keepSecrets bool) (bson.D, any, bson.D) {
+	var id any
+	fields := make(bson.D, 0, len(document))
+	for _, elem := range document {
+		switch {
+		case elem.Key == "_id":
+			id = elem.Value
+		case keepSecrets && slices.Contains(userSecretFields, elem.Key) && elem.Value == redactedValue:
+			// redacted credentials never overwrite stored ones
+		default:
+			fields = append(fields, elem)
+		}
+	}
+
+	if keys, ok := restoreKeys[collection]; ok {
+		filter := make(bson.D, 0, len(keys))
+		for _, key := range keys {
+			for _, elem := range fields {
+				if elem.Key == key {
+					filter = append(filter, elem)
+					break
+				}
+			}
+		}
+		if len(filter) == len(keys) {
+			return filter, id, fields
+		}
+	}
+
+	if id == nil {
+		return nil, nil, fields
+	}
+	return bson.D{{Key: "_id", Value: id}}, id, fields
+}
+
+func readJSONRecord(reader *bufio.Reader, out any) error {
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return err
+		}
+		if len(line) > 0 && line[0] == '{' {
+			return bson.UnmarshalExtJSON(line, true, out)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func readBSONRecord(reader *bufio.Reader, out any) error {
+	var size [4]byte
+	if _, err := io.ReadFull(reader, size[:]); err != nil {
+		return err
+	}
+
+	length := binary.LittleEndian.Uint32(size[:])
+	if length < 5 {
+		return errors.New("invalid BSON document length")
+	}
+
+	data := make([]byte, length)
+	copy(data, size[:])
+	if _, err := io.ReadFull(reader, data[4:]); err != nil {
+		return err
+	}
+	return bson.Unmarshal(data, out)
+}
@@ -0,0 +1,174 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	id := bson.NewObjectID()
+	records := []backupRecord{
+		{Collection: "movies", Document: bson.D{{Key: "_id", Value: id}, {Key: "imdb_id", Value: "tt0078748"}, {Key: "title", Value: "Alien"}}},
+		{Collection: "genres", Document: bson.D{{Key: "genre_id", Value: int32(9)}, {Key: "genre_name", Value: "Horror"}}},
+	}
+
+	tests := []struct {
+		format     string
+		readRecord func(*bufio.Reader, any) error
+	}{
+		{FormatJSONL, readJSONRecord},
+		{FormatBSON, readBSONRecord},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeRecord := recordWriter(&buf, tt.format)
+			manifest := backupManifest{Version: 1, Format: tt.format, Secrets: SecretsExclude, Collections: []string{"movies", "genres"}}
+			if err := writeRecord(manifest); err != nil {
+				t.Fatalf("writing manifest: %v", err)
+			}
+			for _, record := range records {
+				if err := writeRecord(record); err != nil {
+					t.Fatalf("writing record: %v", err)
+				}
+			}
+
+			reader := bufio.NewReader(&buf)
+			var gotManifest backupManifest
+			if err := tt.readRecord(reader, &gotManifest); err != nil {
+				t.Fatalf("reading manifest: %v", err)
+			}
+			if gotManifest.Version != 1 || gotManifest.Format != tt.format || !slices.Equal(gotManifest.Collections, manifest.Collections) {
+				t.Errorf("manifest = %+v, want %+v", gotManifest, manifest)
+			}
+			for _, want := range records {
+				var got backupRecord
+				if err := tt.readRecord(reader, &got); err != nil {
+					t.Fatalf("reading record: %v", err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("record = %v, want %v", got, want)
+				}
+			}
+			var extra backupRecord
+			if err := tt.readRecord(reader, &extra); !errors.Is(err, io.EOF) {
+				t.Errorf("reading past the last record = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestScrubSecrets(t *testing.T) {
+	user := bson.D{
+		{Key: "email", Value: "ripley@example.com"},
+		{Key: "password", Value: "hash"},
+		{Key: "token", Value: "jwt"},
+		{Key: "refresh_token", Value: "refresh"},
+	}
+
+	tests := []struct {
+		secrets string
+		want    bson.D
+	}{
+		{SecretsInclude, user},
+		{SecretsExclude, bson.D{{Key: "email", Value: "ripley@example.com"}}},
+		{SecretsRedact, bson.D{
+			{Key: "email", Value: "ripley@example.com"},
+			{Key: "password", Value: redactedValue},
+			{Key: "token", Value: redactedValue},
+			{Key: "refresh_token", Value: redactedValue},
+		}},
+	}
+
+	for _, tt := range tests {
+		if got := scrubSecrets(user, tt.secrets); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("scrubSecrets(%s) = %v, want %v", tt.secrets, got, tt.want)
+		}
+	}
+}
+
+func TestSplitDocument(t *testing.T) {
+	id := bson.NewObjectID()
+
+	tests := []struct {
+		name        string
+		collection  string
+		document    bson.D
+		keepSecrets bool
+		wantFilter  bson.D
+		wantFields  bson.D
+	}{
+		{
+			name:       "natural key",
+			collection: "movies",
+			document:   bson.D{{Key: "_id", Value: id}, {Key: "imdb_id", Value: "tt0078748"}, {Key: "title", Value: "Alien"}},
+			wantFilter: bson.D{{Key: "imdb_id", Value: "tt0078748"}},
+			wantFields: bson.D{{Key: "imdb_id", Value: "tt0078748"}, {Key: "title", Value: "Alien"}},
+		},
+		{
+			name:       "compound natural key",
+			collection: "user_reviews",
+			document:   bson.D{{Key: "_id", Value: id}, {Key: "user_id", Value: "u1"}, {Key: "imdb_id", Value: "tt1"}, {Key: "rating", Value: int32(4)}},
+			wantFilter: bson.D{{Key: "imdb_id", Value: "tt1"}, {Key: "user_id", Value: "u1"}},
+			wantFields: bson.D{{Key: "user_id", Value: "u1"}, {Key: "imdb_id", Value: "tt1"}, {Key: "rating", Value: int32(4)}},
+		},
+		{
+			name:       "missing natural key falls back to _id",
+			collection: "movies",
+			document:   bson.D{{Key: "_id", Value: id}, {Key: "title", Value: "Alien"}},
+			wantFilter: bson.D{{Key: "_id", Value: id}},
+			wantFields: bson.D{{Key: "title", Value: "Alien"}},
+		},
+		{
+			name:       "collection without a natural key",
+			collection: "watch_sessions",
+			document:   bson.D{{Key: "_id", Value: id}, {Key: "user_id", Value: "u1"}},
+			wantFilter: bson.D{{Key: "_id", Value: id}},
+			wantFields: bson.D{{Key: "user_id", Value: "u1"}},
+		},
+		{
+			name:       "no key at all",
+			collection: "watch_sessions",
+			document:   bson.D{{Key: "user_id", Value: "u1"}},
+			wantFields: bson.D{{Key: "user_id", Value: "u1"}},
+		},
+		{
+			name:        "redacted credentials are dropped",
+			collection:  "users",
+			document:    bson.D{{Key: "_id", Value: id}, {Key: "email", Value: "ripley@example.com"}, {Key: "password", Value: redactedValue}, {Key: "token", Value: "jwt"}},
+			keepSecrets: true,
+			wantFilter:  bson.D{{Key: "email", Value: "ripley@example.com"}},
+			wantFields:  bson.D{{Key: "email", Value: "ripley@example.com"}, {Key: "token", Value: "jwt"}},
+		},
+		{
+			name:       "redacted credentials kept when secrets are included",
+			collection: "users",
+			document:   bson.D{{Key: "email", Value: "ripley@example.com"}, {Key: "password", Value: redactedValue}},
+			wantFilter: bson.D{{Key: "email", Value: "ripley@example.com"}},
+			wantFields: bson.D{{Key: "email", Value: "ripley@example.com"}, {Key: "password", Value: redactedValue}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, gotID, fields := splitDocument(tt.collection, tt.document, tt.keepSecrets)
+			if !reflect.DeepEqual(filter, tt.wantFilter) {
+				t.Errorf("filter = %v, want %v", filter, tt.wantFilter)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", fields, tt.wantFields)
+			}
+			if tt.document[0].Key == "_id" && gotID != id {
+				t.Errorf("id = %v, want %v", gotID, id)
+			}
+		})
+	}
+}
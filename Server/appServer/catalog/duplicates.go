@@ -0,0 +1,110 @@
+package catalog
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/search"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// articles ignored at the start of a title when comparing
+var leadingArticles = []string{"the", "a", "an"}
+
+// FindDuplicateTitles groups movies whose normalized titles are at least
+// threshold similar (0 to 1). Groups are ordered most similar first.
+func FindDuplicateTitles(ctx context.Context, client *mongo.Client, threshold float64) ([]models.DuplicateTitleGroup, error) {
+	movieCollection := database.OpenCollection("movies", client)
+
+	projection := options.Find().SetProjection(bson.M{"imdb_id": 1, "title": 1, "poster_path": 1})
+	cursor, err := movieCollection.Find(ctx, bson.M{"deleted_at": nil}, projection)
+	if err != nil {
+		return nil, err
+	}
+	var movies []models.Movie
+	if err := cursor.All(ctx, &movies); err != nil {
+		return nil, err
+	}
+
+	titles := make([]string, len(movies))
+	for i, movie := range movies {
+		titles[i] = normalizeTitle(movie.Title)
+	}
+
+	// union-find over every pair of movies that clears the threshold
+	parent := make([]int, len(movies))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	best := map[int]float64{}
+	for i := range movies {
+		for j := i + 1; j < len(movies); j++ {
+			// titles this different in length can never clear the threshold
+			shorter, longer := len(titles[i]), len(titles[j])
+			if shorter > longer {
+				shorter, longer = longer, shorter
+			}
+			if longer > 0 && float64(shorter)/float64(longer) < threshold {
+				continue
+			}
+
+			similarity := search.Similarity(titles[i], titles[j])
+			if similarity < threshold {
+				continue
+			}
+			ri, rj := find(i), find(j)
+			if ri != rj {
+				parent[rj] = ri
+				best[ri] = max(best[ri], best[rj])
+			}
+			best[ri] = max(best[ri], similarity)
+		}
+	}
+
+	groups := map[int]*models.DuplicateTitleGroup{}
+	for i, movie := range movies {
+		root := find(i)
+		if _, ok := best[root]; !ok {
+			continue
+		}
+		group, ok := groups[root]
+		if !ok {
+			group = &models.DuplicateTitleGroup{Similarity: best[root]}
+			groups[root] = group
+		}
+		group.Movies = append(group.Movies, movie)
+	}
+
+	result := make([]models.DuplicateTitleGroup, 0, len(groups))
+	for _, group := range groups {
+		if len(group.Movies) > 1 {
+			result = append(result, *group)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Similarity > result[j].Similarity
+	})
+	return result, nil
+}
+
+func normalizeTitle(title string) string {
+	words := search.Tokenize(title)
+	for len(words) > 1 && slices.Contains(leadingArticles, words[0]) {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
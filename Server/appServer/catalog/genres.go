@@ -0,0 +1,159 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var ErrGenreNotFound = errors.New("genre not found")
+
+// Number of embedded genre copies touched by a rename or retirement
+type GenrePropagation struct {
+	MoviesUpdated int64 `json:"movies_updated"`
+	UsersUpdated  int64 `json:"users_updated"`
+}
+
+// activeGenres matches genres that have not been retired.
+func activeGenres() bson.M {
+	return bson.M{"retired_at": nil}
+}
+
+// LoadGenres returns every genre that has not been retired, keyed by
+// genre_id. Retired genres cannot be given to movies or users.
+func LoadGenres(ctx context.Context, client *mongo.Client) (map[int]models.Genre, error) {
+	cursor, err := database.OpenCollection("genres", client).Find(ctx, activeGenres())
+	if err != nil {
+		return nil, err
+	}
+
+	var genres []models.Genre
+	if err := cursor.All(ctx, &genres); err != nil {
+		return nil, err
+	}
+
+	known := make(map[int]models.Genre, len(genres))
+	for _, genre := range genres {
+		known[genre.GenreID] = genre
+	}
+	return known, nil
+}
+
+// CanonicalGenres checks every genre id against known and returns the
+// genres with their stored names, so embedded copies never drift from the
+// genres collection.
+func CanonicalGenres(known map[int]models.Genre, genres []models.Genre) ([]models.Genre, error) {
+	canonical := make([]models.Genre, 0, len(genres))
+	var unknown []string
+
+	for _, genre := range genres {
+		stored, ok := known[genre.GenreID]
+		if !ok {
+			unknown = append(unknown, strconv.Itoa(genre.GenreID))
+			continue
+		}
+		canonical = append(canonical, stored)
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown or retired genre ids: %s", strings.Join(unknown, ", "))
+	}
+	return canonical, nil
+}
+
+// NextGenreID returns one more than the highest genre_id in use, retired
+// genres included.
+func NextGenreID(ctx context.Context, client *mongo.Client) (int, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "genre_id", Value: -1}})
+
+	var last models.Genre
+	err := database.OpenCollection("genres", client).FindOne(ctx, bson.D{}, opts).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return last.GenreID + 1, nil
+}
+
+// RenameGenre renames a genre and every copy of it embedded in movies and
+// in users' favourite genres.
+func RenameGenre(ctx context.Context, client *mongo.Client, genreID int, name string) (GenrePropagation, error) {
+	var propagation GenrePropagation
+
+	result, err := database.OpenCollection("genres", client).UpdateOne(ctx,
+		bson.M{"genre_id": genreID},
+		bson.M{"$set": bson.M{"genre_name": name}},
+	)
+	if err != nil {
+		return propagation, err
+	}
+	if result.MatchedCount == 0 {
+		return propagation, ErrGenreNotFound
+	}
+
+	arrayFilter := options.UpdateMany().SetArrayFilters([]any{bson.M{"g.genre_id": genreID}})
+
+	movies, err := database.OpenCollection("movies", client).UpdateMany(ctx,
+		bson.M{"genre.genre_id": genreID},
+		bson.M{"$set": bson.M{"genre.$[g].genre_name": name}},
+		arrayFilter,
+	)
+	if err != nil {
+		return propagation, err
+	}
+	propagation.MoviesUpdated = movies.ModifiedCount
+
+	users, err := database.OpenCollection("users", client).UpdateMany(ctx,
+		bson.M{"favourite_genres.genre_id": genreID},
+		bson.M{"$set": bson.M{"favourite_genres.$[g].genre_name": name}},
+		arrayFilter,
+	)
+	if err != nil {
+		return propagation, err
+	}
+	propagation.UsersUpdated = users.ModifiedCount
+
+	return propagation, nil
+}
+
+// RetireGenre hides a genre from listings and from new movies and
+// registrations, and removes it from users' favourite genres. Movies keep
+// the genre so none is left without one.
+func RetireGenre(ctx context.Context, client *mongo.Client, genreID int) (GenrePropagation, error) {
+	var propagation GenrePropagation
+
+	filter := activeGenres()
+	filter["genre_id"] = genreID
+	result, err := database.OpenCollection("genres", client).UpdateOne(ctx,
+		filter,
+		bson.M{"$set": bson.M{"retired_at": time.Now()}},
+	)
+	if err != nil {
+		return propagation, err
+	}
+	if result.MatchedCount == 0 {
+		return propagation, ErrGenreNotFound
+	}
+
+	users, err := database.OpenCollection("users", client).UpdateMany(ctx,
+		bson.M{"favourite_genres.genre_id": genreID},
+		bson.M{"$pull": bson.M{"favourite_genres": bson.M{"genre_id": genreID}}},
+	)
+	if err != nil {
+		return propagation, err
+	}
+	propagation.UsersUpdated = users.ModifiedCount
+
+	return propagation, nil
+}
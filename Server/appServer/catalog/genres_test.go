@@ -0,0 +1,52 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+)
+
+func TestCanonicalGenres(t *testing.T) {
+	known := map[int]models.Genre{
+		1: {GenreID: 1, GenreName: "Comedy"},
+		2: {GenreID: 2, GenreName: "Drama"},
+	}
+
+	tests := []struct {
+		name    string
+		genres  []models.Genre
+		want    []models.Genre
+		wantErr string
+	}{
+		{name: "none", genres: nil, want: []models.Genre{}},
+		{
+			name:   "stale names replaced",
+			genres: []models.Genre{{GenreID: 2, GenreName: "drama"}, {GenreID: 1}},
+			want:   []models.Genre{{GenreID: 2, GenreName: "Drama"}, {GenreID: 1, GenreName: "Comedy"}},
+		},
+		{
+			name:    "unknown ids listed",
+			genres:  []models.Genre{{GenreID: 1}, {GenreID: 7}, {GenreID: 9}},
+			wantErr: "unknown or retired genre ids: 7, 9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalGenres(known, tt.genres)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("CanonicalGenres error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CanonicalGenres error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CanonicalGenres = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
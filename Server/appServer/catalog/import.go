@@ -0,0 +1,305 @@
+package catalog
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+const (
+	RowCreated  = "created"
+	RowUpdated  = "updated"
+	RowRejected = "rejected"
+)
+
+// columns a CSV import must provide; admin_review is optional
+var requiredColumns = []string{"imdb_id", "title", "poster_path", "youtube_id", "genre", "ranking_value", "ranking_name"}
+
+var validate = validator.New()
+
+// ErrInvalidImport reports an import file that cannot be read at all, as
+// opposed to a bad row or a database failure.
+var ErrInvalidImport = errors.New("invalid import file")
+
+// FormatFromFilename guesses the import format from a file extension.
+func FormatFromFilename(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	}
+	return ""
+}
+
+// ImportMovies reads movies in the given format from r, validates each row
+// against the models.Movie tags and upserts it on imdb_id. A bad row is
+// reported as rejected and does not stop the import. Ranking changes are
+// recorded as import decisions by importedBy, and locked rankings are kept.
+//
+// CSV files need a header row naming the columns. The genre column holds
+// id:name pairs separated by "|", for example "1:Comedy|2:Drama". An
+// unreadable file is reported as ErrInvalidImport.
+func ImportMovies(ctx context.Context, client *mongo.Client, r io.Reader, format, importedBy string) (models.ImportReport, error) {
+	report := models.ImportReport{Rows: []models.ImportRowResult{}}
+
+	knownGenres, err := LoadGenres(ctx, client)
+	if err != nil {
+		return report, err
+	}
+
+	importRow := func(row int, movie models.Movie, parseErr error) error {
+		result := models.ImportRowResult{Row: row, ImdbID: movie.ImdbID}
+
+		if parseErr == nil {
+			parseErr = validate.Struct(&movie)
+		}
+		if parseErr == nil {
+			movie.Genre, parseErr = CanonicalGenres(knownGenres, movie.Genre)
+		}
+		if parseErr != nil {
+			result.Status = RowRejected
+			result.Error = parseErr.Error()
+			report.Rejected++
+			report.Rows = append(report.Rows, result)
+			return nil
+		}
+
+		status, err := upsertMovie(ctx, client, movie, importedBy)
+		if errors.Is(err, errMovieDeleted) {
+			result.Status = RowRejected
+			result.Error = err.Error()
+			report.Rejected++
+			report.Rows = append(report.Rows, result)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("row %d: %w", row, err)
+		}
+		result.Status = status
+		if status == RowCreated {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+		report.Rows = append(report.Rows, result)
+		return nil
+	}
+
+	switch format {
+	case FormatCSV:
+		err = readCSV(r, importRow)
+	case FormatJSONL:
+		err = readJSONL(r, importRow)
+	default:
+		return report, fmt.Errorf("%w: unsupported import format %q", ErrInvalidImport, format)
+	}
+	return report, err
+}
+
+// errMovieDeleted rejects a row whose imdb_id belongs to a soft-deleted
+// movie; an import never restores a deleted movie.
+var errMovieDeleted = errors.New("movie with this imdb_id is deleted")
+
+// upsertMovie inserts or replaces the editable fields of movie. An empty
+// admin_review leaves the stored review alone. The ranking of an existing
+// movie only changes when it is not locked.
+func upsertMovie(ctx context.Context, client *mongo.Client, movie models.Movie, importedBy string) (string, error) {
+	movieCollection := database.OpenCollection("movies", client)
+
+	fields := bson.M{
+		"imdb_id":     movie.ImdbID,
+		"title":       movie.Title,
+		"poster_path": movie.PosterPath,
+		"youtube_id":  movie.YoutubeID,
+		"genre":       movie.Genre,
+	}
+	onInsert := bson.M{"ranking": movie.Ranking}
+	if movie.AdminReview != "" {
+		fields["admin_review"] = movie.AdminReview
+	} else {
+		onInsert["admin_review"] = ""
+	}
+
+	filter := bson.M{"imdb_id": movie.ImdbID, "deleted_at": nil}
+	update := bson.M{"$set": fields, "$setOnInsert": onInsert}
+
+	result, err := movieCollection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// the only document left with this imdb_id is a deleted one
+		return "", errMovieDeleted
+	}
+	if err != nil {
+		return "", err
+	}
+	status := RowUpdated
+	if result.UpsertedCount > 0 {
+		status = RowCreated
+	} else {
+		result, err = movieCollection.UpdateOne(ctx,
+			bson.M{
+				"imdb_id":        movie.ImdbID,
+				"ranking_locked": bson.M{"$ne": true},
+				"$or": bson.A{
+					bson.M{"ranking.ranking_value": bson.M{"$ne": movie.Ranking.RankingValue}},
+					bson.M{"ranking.ranking_name": bson.M{"$ne": movie.Ranking.RankingName}},
+				},
+			},
+			bson.M{"$set": bson.M{"ranking": movie.Ranking}},
+		)
+		if err != nil || result.ModifiedCount == 0 {
+			return status, err
+		}
+	}
+
+	return status, RecordRankingDecision(ctx, client, models.RankingDecision{
+		ImdbID:      movie.ImdbID,
+		Ranking:     movie.Ranking,
+		Source:      models.DecisionImport,
+		AdminReview: movie.AdminReview,
+		DecidedBy:   importedBy,
+	})
+}
+
+type rowFunc func(row int, movie models.Movie, parseErr error) error
+
+func readJSONL(r io.Reader, importRow rowFunc) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var movie models.Movie
+		err := json.Unmarshal([]byte(text), &movie)
+		if err := importRow(line, movie, err); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: line %d: %v", ErrInvalidImport, line+1, err)
+	}
+	return nil
+}
+
+func readCSV(r io.Reader, importRow rowFunc) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("%w: reading CSV header: %v", ErrInvalidImport, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("%w: CSV header is missing the %s column", ErrInvalidImport, name)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		var line int
+		var movie models.Movie
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			line = parseErr.StartLine
+		case err != nil:
+			return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		default:
+			line, _ = reader.FieldPos(0)
+			movie, err = movieFromRecord(record, columns)
+		}
+		if err := importRow(line, movie, err); err != nil {
+			return err
+		}
+	}
+}
+
+func movieFromRecord(record []string, columns map[string]int) (models.Movie, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	movie := models.Movie{
+		ImdbID:      field("imdb_id"),
+		Title:       field("title"),
+		PosterPath:  field("poster_path"),
+		YoutubeID:   field("youtube_id"),
+		AdminReview: field("admin_review"),
+		Ranking:     models.Ranking{RankingName: field("ranking_name")},
+	}
+
+	rankingValue, err := strconv.Atoi(field("ranking_value"))
+	if err != nil {
+		return movie, errors.New("ranking_value must be an integer")
+	}
+	movie.Ranking.RankingValue = rankingValue
+
+	genres, err := parseGenres(field("genre"))
+	if err != nil {
+		return movie, err
+	}
+	movie.Genre = genres
+
+	return movie, nil
+}
+
+func parseGenres(value string) ([]models.Genre, error) {
+	var genres []models.Genre
+
+	for _, pair := range strings.Split(value, "|") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		idStr, name, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("genre %q must be formatted as id:name", pair)
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(idStr))
+		if err != nil {
+			return nil, fmt.Errorf("genre id %q must be an integer", idStr)
+		}
+		genres = append(genres, models.Genre{GenreID: id, GenreName: strings.TrimSpace(name)})
+	}
+
+	return genres, nil
+}
@@ -0,0 +1,139 @@
+package catalog
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+)
+
+func TestParseGenres(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []models.Genre
+		wantErr string
+	}{
+		{value: "", want: nil},
+		{value: "1:Comedy", want: []models.Genre{{GenreID: 1, GenreName: "Comedy"}}},
+		{
+			value: " 1 : Comedy | 2:Drama |",
+			want:  []models.Genre{{GenreID: 1, GenreName: "Comedy"}, {GenreID: 2, GenreName: "Drama"}},
+		},
+		{value: "Comedy", wantErr: `genre "Comedy" must be formatted as id:name`},
+		{value: "x:Comedy", wantErr: `genre id "x" must be an integer`},
+	}
+
+	for _, tt := range tests {
+		got, err := parseGenres(tt.value)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("parseGenres(%q) error = %v, want %q", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("parseGenres(%q) error: %v", tt.value, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseGenres(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMovieFromRecord(t *testing.T) {
+	columns := map[string]int{
+		"imdb_id": 0, "title": 1, "poster_path": 2, "youtube_id": 3,
+		"genre": 4, "ranking_value": 5, "ranking_name": 6, "admin_review": 7,
+	}
+
+	tests := []struct {
+		name    string
+		record  []string
+		want    models.Movie
+		wantErr string
+	}{
+		{
+			name:   "full row",
+			record: []string{" tt0078748 ", "Alien", "https://img/alien.jpg", "LjLamj-b0I8", "9:Horror|10:Sci-Fi", "1", "Excellent", "Tense."},
+			want: models.Movie{
+				ImdbID:      "tt0078748",
+				Title:       "Alien",
+				PosterPath:  "https://img/alien.jpg",
+				YoutubeID:   "LjLamj-b0I8",
+				Genre:       []models.Genre{{GenreID: 9, GenreName: "Horror"}, {GenreID: 10, GenreName: "Sci-Fi"}},
+				Ranking:     models.Ranking{RankingValue: 1, RankingName: "Excellent"},
+				AdminReview: "Tense.",
+			},
+		},
+		{
+			name:   "short row leaves missing columns empty",
+			record: []string{"tt0078748", "Alien", "", "", "9:Horror", "2", "Good"},
+			want: models.Movie{
+				ImdbID:  "tt0078748",
+				Title:   "Alien",
+				Genre:   []models.Genre{{GenreID: 9, GenreName: "Horror"}},
+				Ranking: models.Ranking{RankingValue: 2, RankingName: "Good"},
+			},
+		},
+		{
+			name:    "ranking value not a number",
+			record:  []string{"tt0078748", "Alien", "", "", "9:Horror", "two", "Good"},
+			wantErr: "ranking_value must be an integer",
+		},
+		{
+			name:    "bad genre",
+			record:  []string{"tt0078748", "Alien", "", "", "Horror", "2", "Good"},
+			wantErr: `genre "Horror" must be formatted as id:name`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := movieFromRecord(tt.record, columns)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("movieFromRecord error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("movieFromRecord error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("movieFromRecord = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCSVHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "empty file", input: "", wantErr: "reading CSV header"},
+		{name: "missing column", input: "imdb_id,title,poster_path,youtube_id,genre,ranking_value\n", wantErr: "missing the ranking_name column"},
+		{name: "header only", input: "IMDB_ID, Title,poster_path,youtube_id,genre,ranking_value,ranking_name\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := readCSV(strings.NewReader(tt.input), func(int, models.Movie, error) error {
+				t.Fatal("header-only input produced a row")
+				return nil
+			})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("readCSV error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidImport) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("readCSV error = %v, want ErrInvalidImport containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
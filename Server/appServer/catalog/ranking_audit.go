@@ -0,0 +1,126 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var ErrMovieNotFound = errors.New("movie not found")
+
+// RecordRankingDecision appends a decision to the ranking audit trail.
+func RecordRankingDecision(ctx context.Context, client *mongo.Client, decision models.RankingDecision) error {
+	decision.ID = bson.NewObjectID()
+	if decision.CreatedAt.IsZero() {
+		decision.CreatedAt = time.Now()
+	}
+
+	_, err := database.OpenCollection("ranking_decisions", client).InsertOne(ctx, decision)
+	return err
+}
+
+// RecordRankingDecisions appends several decisions at once.
+func RecordRankingDecisions(ctx context.Context, client *mongo.Client, decisions []models.RankingDecision) error {
+	if len(decisions) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for i := range decisions {
+		decisions[i].ID = bson.NewObjectID()
+		decisions[i].CreatedAt = now
+	}
+	_, err := database.OpenCollection("ranking_decisions", client).InsertMany(ctx, decisions)
+	return err
+}
+
+// RankingHistory returns the newest limit decisions made for a movie.
+func RankingHistory(ctx context.Context, client *mongo.Client, imdbID string, limit int64) ([]models.RankingDecision, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+
+	cursor, err := database.OpenCollection("ranking_decisions", client).Find(ctx, bson.M{"imdb_id": imdbID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions := []models.RankingDecision{}
+	if err := cursor.All(ctx, &decisions); err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
+
+// OverrideRanking sets a movie's ranking by hand and records the override.
+// A locked ranking is left alone by review updates and re-ranking batches
+// until it is unlocked.
+func OverrideRanking(ctx context.Context, client *mongo.Client, imdbID string, value int, locked bool, reason, decidedBy string) (models.Movie, error) {
+	var level models.Ranking
+	err := database.OpenCollection("rankings", client).FindOne(ctx,
+		bson.M{"ranking_value": value, "retired": bson.M{"$ne": true}},
+	).Decode(&level)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Movie{}, ErrRankingNotFound
+	}
+	if err != nil {
+		return models.Movie{}, err
+	}
+
+	var movie models.Movie
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"embedding": 0})
+	err = database.OpenCollection("movies", client).FindOneAndUpdate(ctx,
+		bson.M{"imdb_id": imdbID, "deleted_at": nil},
+		bson.M{"$set": bson.M{"ranking": movieRanking(level), "ranking_locked": locked}},
+		opts,
+	).Decode(&movie)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return movie, ErrMovieNotFound
+	}
+	if err != nil {
+		return movie, err
+	}
+
+	err = RecordRankingDecision(ctx, client, models.RankingDecision{
+		ImdbID:      imdbID,
+		Ranking:     movie.Ranking,
+		Source:      models.DecisionOverride,
+		AdminReview: movie.AdminReview,
+		Locked:      locked,
+		Reason:      reason,
+		DecidedBy:   decidedBy,
+	})
+	return movie, err
+}
+
+// SetRankingLock locks or unlocks a movie's current ranking and records
+// the change.
+func SetRankingLock(ctx context.Context, client *mongo.Client, imdbID string, locked bool, decidedBy string) (models.Movie, error) {
+	var movie models.Movie
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"embedding": 0})
+	err := database.OpenCollection("movies", client).FindOneAndUpdate(ctx,
+		bson.M{"imdb_id": imdbID, "deleted_at": nil},
+		bson.M{"$set": bson.M{"ranking_locked": locked}},
+		opts,
+	).Decode(&movie)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return movie, ErrMovieNotFound
+	}
+	if err != nil {
+		return movie, err
+	}
+
+	err = RecordRankingDecision(ctx, client, models.RankingDecision{
+		ImdbID:      imdbID,
+		Ranking:     movie.Ranking,
+		Source:      models.DecisionLock,
+		AdminReview: movie.AdminReview,
+		Locked:      locked,
+		DecidedBy:   decidedBy,
+	})
+	return movie, err
+}
@@ -0,0 +1,293 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// legacy ranking_value that marked the "unranked" level before the flag existed
+const unrankedSentinel = 999
+
+var (
+	ErrRankingNotFound     = errors.New("ranking not found")
+	ErrRankingConflict     = errors.New("ranking value or name already in use")
+	ErrInvalidRankingOrder = errors.New("invalid ranking order")
+)
+
+// ListRankings returns the ranking scale ordered by value. Retired levels
+// are only included when includeRetired is set.
+func ListRankings(ctx context.Context, client *mongo.Client, includeRetired bool) ([]models.Ranking, error) {
+	filter := bson.M{}
+	if !includeRetired {
+		filter["retired"] = bson.M{"$ne": true}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "ranking_value", Value: 1}})
+	cursor, err := database.OpenCollection("rankings", client).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rankings := []models.Ranking{}
+	if err := cursor.All(ctx, &rankings); err != nil {
+		return nil, err
+	}
+	return rankings, nil
+}
+
+// CreateRanking adds a level to the scale. Only one level may be the
+// unranked one.
+func CreateRanking(ctx context.Context, client *mongo.Client, ranking models.Ranking) error {
+	active, err := ListRankings(ctx, client, false)
+	if err != nil {
+		return err
+	}
+	for _, existing := range active {
+		if existing.RankingValue == ranking.RankingValue || existing.RankingName == ranking.RankingName {
+			return ErrRankingConflict
+		}
+		if existing.Unranked && ranking.Unranked {
+			return fmt.Errorf("%w: %s is already the unranked level", ErrRankingConflict, existing.RankingName)
+		}
+	}
+
+	ranking.Retired = false
+	_, err = database.OpenCollection("rankings", client).InsertOne(ctx, ranking)
+	return err
+}
+
+// UpdateRanking renames and/or moves the level currently at value and
+// re-maps every movie carrying it. It returns the number of movies updated.
+func UpdateRanking(ctx context.Context, client *mongo.Client, value int, updated models.Ranking, decidedBy string) (int64, error) {
+	active, err := ListRankings(ctx, client, false)
+	if err != nil {
+		return 0, err
+	}
+
+	var current *models.Ranking
+	for i := range active {
+		if active[i].RankingValue == value {
+			current = &active[i]
+		}
+	}
+	if current == nil {
+		return 0, ErrRankingNotFound
+	}
+
+	if updated.RankingName == "" {
+		updated.RankingName = current.RankingName
+	}
+	if updated.RankingValue == 0 {
+		updated.RankingValue = current.RankingValue
+	}
+	updated.Unranked = current.Unranked
+
+	for _, existing := range active {
+		if existing.RankingValue == current.RankingValue {
+			continue
+		}
+		if existing.RankingValue == updated.RankingValue || existing.RankingName == updated.RankingName {
+			return 0, ErrRankingConflict
+		}
+	}
+
+	return remapRanking(ctx, client, *current, updated, decidedBy)
+}
+
+// ReorderRankings assigns values 1..n to the active ranked levels in the
+// order of names and re-maps movies to the new values. The unranked level
+// keeps its value. The levels and movies move in one transaction, so a
+// failure part way leaves the scale as it was. It returns the number of
+// movies updated.
+func ReorderRankings(ctx context.Context, client *mongo.Client, names []string, decidedBy string) (int64, error) {
+	session, err := client.StartSession()
+	if err != nil {
+		return 0, err
+	}
+	defer session.EndSession(ctx)
+
+	moved, err := session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		active, err := ListRankings(ctx, client, false)
+		if err != nil {
+			return nil, err
+		}
+		moves, err := planReorder(active, names)
+		if err != nil {
+			return nil, err
+		}
+
+		var moved int64
+		for _, move := range moves {
+			count, err := remapRanking(ctx, client, move.from, move.to, decidedBy)
+			if err != nil {
+				return nil, err
+			}
+			moved += count
+		}
+		return moved, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved.(int64), nil
+}
+
+type rankingMove struct {
+	from, to models.Ranking
+}
+
+// planReorder returns the levels of active whose value changes when the
+// ranked levels take values 1..n in the order of names. Names are unique,
+// so remapping on value and name keeps a level that already moved from
+// being picked up by a later one.
+func planReorder(active []models.Ranking, names []string) ([]rankingMove, error) {
+	byName := map[string]models.Ranking{}
+	for _, ranking := range active {
+		if !ranking.Unranked {
+			byName[ranking.RankingName] = ranking
+		}
+	}
+	if len(names) != len(byName) {
+		return nil, fmt.Errorf("%w: order must list each of the %d ranked levels exactly once", ErrInvalidRankingOrder, len(byName))
+	}
+
+	var moves []rankingMove
+	seen := map[string]bool{}
+	for i, name := range names {
+		ranking, ok := byName[name]
+		if !ok || seen[name] {
+			return nil, fmt.Errorf("%w: order must list each of the %d ranked levels exactly once", ErrInvalidRankingOrder, len(byName))
+		}
+		seen[name] = true
+
+		updated := ranking
+		updated.RankingValue = i + 1
+		if updated.RankingValue != ranking.RankingValue {
+			moves = append(moves, rankingMove{from: ranking, to: updated})
+		}
+	}
+	return moves, nil
+}
+
+// RetireRanking hides the level at value from the scale and moves its
+// movies to replacementValue, or to the unranked level when that is zero.
+// It returns the number of movies updated.
+func RetireRanking(ctx context.Context, client *mongo.Client, value, replacementValue int, decidedBy string) (int64, error) {
+	active, err := ListRankings(ctx, client, false)
+	if err != nil {
+		return 0, err
+	}
+
+	var retired, replacement *models.Ranking
+	for i := range active {
+		switch {
+		case active[i].RankingValue == value:
+			retired = &active[i]
+		case replacementValue != 0 && active[i].RankingValue == replacementValue:
+			replacement = &active[i]
+		case replacementValue == 0 && active[i].Unranked:
+			replacement = &active[i]
+		}
+	}
+	if retired == nil {
+		return 0, ErrRankingNotFound
+	}
+	if replacement == nil {
+		return 0, errors.New("no replacement ranking for the retired level's movies")
+	}
+
+	_, err = database.OpenCollection("rankings", client).UpdateOne(ctx,
+		bson.M{"ranking_value": retired.RankingValue, "ranking_name": retired.RankingName},
+		bson.M{"$set": bson.M{"retired": true}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return moveMovies(ctx, client, *retired, *replacement,
+		fmt.Sprintf("level %s retired", retired.RankingName), decidedBy)
+}
+
+func remapRanking(ctx context.Context, client *mongo.Client, current, updated models.Ranking, decidedBy string) (int64, error) {
+	_, err := database.OpenCollection("rankings", client).UpdateOne(ctx,
+		bson.M{"ranking_value": current.RankingValue, "ranking_name": current.RankingName, "retired": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"ranking_value": updated.RankingValue, "ranking_name": updated.RankingName}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return moveMovies(ctx, client, current, updated,
+		fmt.Sprintf("level %s (%d) changed to %s (%d)", current.RankingName, current.RankingValue, updated.RankingName, updated.RankingValue), decidedBy)
+}
+
+// moveMovies moves every movie on level from to level to and records a
+// scale change decision for each. Locked movies move too, keeping their
+// lock, since the level they were locked to no longer exists as it was.
+func moveMovies(ctx context.Context, client *mongo.Client, from, to models.Ranking, reason, decidedBy string) (int64, error) {
+	movies := database.OpenCollection("movies", client)
+	filter := bson.M{"ranking.ranking_value": from.RankingValue, "ranking.ranking_name": from.RankingName}
+
+	cursor, err := movies.Find(ctx, filter, options.Find().SetProjection(bson.M{"imdb_id": 1, "admin_review": 1, "ranking_locked": 1}))
+	if err != nil {
+		return 0, err
+	}
+	var affected []models.Movie
+	if err := cursor.All(ctx, &affected); err != nil {
+		return 0, err
+	}
+
+	ranking := movieRanking(to)
+	result, err := movies.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"ranking": ranking}})
+	if err != nil {
+		return 0, err
+	}
+
+	decisions := make([]models.RankingDecision, len(affected))
+	for i, movie := range affected {
+		decisions[i] = models.RankingDecision{
+			ImdbID:      movie.ImdbID,
+			Ranking:     ranking,
+			Source:      models.DecisionScaleChange,
+			AdminReview: movie.AdminReview,
+			Locked:      movie.RankingLocked,
+			Reason:      reason,
+			DecidedBy:   decidedBy,
+		}
+	}
+	return result.ModifiedCount, RecordRankingDecisions(ctx, client, decisions)
+}
+
+// movieRanking is the copy of a level embedded in a movie.
+func movieRanking(ranking models.Ranking) models.Ranking {
+	return models.Ranking{
+		RankingValue: ranking.RankingValue,
+		RankingName:  ranking.RankingName,
+		Unranked:     ranking.Unranked,
+	}
+}
+
+// MigrateUnrankedSentinel flags the legacy 999 ranking level, and the
+// movies that carry it, as unranked.
+func MigrateUnrankedSentinel(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("rankings", client).UpdateMany(ctx,
+		bson.M{"ranking_value": unrankedSentinel, "unranked": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"unranked": true}},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = database.OpenCollection("movies", client).UpdateMany(ctx,
+		bson.M{"ranking.ranking_value": unrankedSentinel, "ranking.unranked": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"ranking.unranked": true}},
+	)
+	return err
+}
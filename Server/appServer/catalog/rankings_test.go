@@ -0,0 +1,64 @@
+package catalog
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+)
+
+func TestPlanReorder(t *testing.T) {
+	excellent := models.Ranking{RankingValue: 1, RankingName: "Excellent"}
+	good := models.Ranking{RankingValue: 2, RankingName: "Good"}
+	bad := models.Ranking{RankingValue: 3, RankingName: "Bad"}
+	unranked := models.Ranking{RankingValue: unrankedSentinel, RankingName: "Not_Ranked", Unranked: true}
+	active := []models.Ranking{excellent, good, bad, unranked}
+
+	moved := func(ranking models.Ranking, value int) rankingMove {
+		to := ranking
+		to.RankingValue = value
+		return rankingMove{from: ranking, to: to}
+	}
+
+	tests := []struct {
+		name    string
+		names   []string
+		want    []rankingMove
+		wantErr bool
+	}{
+		{name: "same order", names: []string{"Excellent", "Good", "Bad"}},
+		{
+			name:  "swap",
+			names: []string{"Good", "Excellent", "Bad"},
+			want:  []rankingMove{moved(good, 1), moved(excellent, 2)},
+		},
+		{
+			name:  "reverse",
+			names: []string{"Bad", "Good", "Excellent"},
+			want:  []rankingMove{moved(bad, 1), moved(excellent, 3)},
+		},
+		{name: "missing level", names: []string{"Excellent", "Good"}, wantErr: true},
+		{name: "repeated level", names: []string{"Excellent", "Good", "Good"}, wantErr: true},
+		{name: "unknown level", names: []string{"Excellent", "Good", "Awful"}, wantErr: true},
+		{name: "unranked level listed", names: []string{"Excellent", "Good", "Not_Ranked"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := planReorder(active, tt.names)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRankingOrder) {
+					t.Fatalf("planReorder(%q) error = %v, want ErrInvalidRankingOrder", tt.names, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("planReorder(%q) error: %v", tt.names, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planReorder(%q) = %v, want %v", tt.names, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrReviewNotFound   = errors.New("review not found")
+	ErrOwnReview        = errors.New("you cannot report your own review")
+	ErrAlreadyReported  = errors.New("you have already reported this review")
+	ErrNotModerationDue = errors.New("review is not waiting for moderation")
+)
+
+// visibleReviews matches reviews shown to users and counted in ratings.
+// Reviews from before moderation have no status and count as published.
+func visibleReviews() bson.M {
+	return bson.M{"moderation.status": bson.M{"$nin": bson.A{models.ReviewHidden, models.ReviewRejected}}}
+}
+
+// SaveReview creates the user's review of a movie or replaces their
+// earlier one, then refreshes the movie's rating average and count. The
+// review's moderation replaces the stored one; callers carry over a hidden
+// or rejected verdict.
+// The bool reports whether the review is new.
+func SaveReview(ctx context.Context, client *mongo.Client, review models.UserReview) (models.UserReview, bool, error) {
+	if err := requireMovie(ctx, client, review.ImdbID); err != nil {
+		return review, false, err
+	}
+
+	now := time.Now()
+	result, err := reviews(client).UpdateOne(ctx,
+		bson.M{"imdb_id": review.ImdbID, "user_id": review.UserID},
+		bson.M{
+			"$set": bson.M{
+				"user_name":  review.UserName,
+				"rating":     review.Rating,
+				"body":       review.Body,
+				"moderation": review.Moderation,
+				"updated_at": now,
+			},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		return review, false, err
+	}
+	if err := refreshRating(ctx, client, review.ImdbID); err != nil {
+		return review, false, err
+	}
+
+	saved, err := UserReviewOf(ctx, client, review.ImdbID, review.UserID)
+	return saved, result.UpsertedCount > 0, err
+}
+
+// UserReviewOf returns one user's review of a movie.
+func UserReviewOf(ctx context.Context, client *mongo.Client, imdbID, userID string) (models.UserReview, error) {
+	var review models.UserReview
+	err := reviews(client).FindOne(ctx, bson.M{"imdb_id": imdbID, "user_id": userID}).Decode(&review)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return review, ErrReviewNotFound
+	}
+	return review, err
+}
+
+// DeleteReview removes the user's review of a movie and refreshes the
+// movie's rating.
+func DeleteReview(ctx context.Context, client *mongo.Client, imdbID, userID string) error {
+	result, err := reviews(client).DeleteOne(ctx, bson.M{"imdb_id": imdbID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrReviewNotFound
+	}
+	return refreshRating(ctx, client, imdbID)
+}
+
+// ListReviews pages through a movie's visible reviews, newest first,
+// without their moderation details or reviewer ids. It returns
+// ErrMovieNotFound for a missing or deleted movie.
+func ListReviews(ctx context.Context, client *mongo.Client, imdbID string, page, limit int64) (models.ReviewListResponse, error) {
+	if err := requireMovie(ctx, client, imdbID); err != nil {
+		return models.ReviewListResponse{Page: page, Limit: limit}, err
+	}
+
+	filter := visibleReviews()
+	filter["imdb_id"] = imdbID
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetProjection(bson.M{"moderation": 0, "reports": 0, "user_id": 0})
+	return pageReviews(ctx, client, filter, opts, page, limit)
+}
+
+func pageReviews(ctx context.Context, client *mongo.Client, filter bson.M, opts *options.FindOptionsBuilder, page, limit int64) (models.ReviewListResponse, error) {
+	response := models.ReviewListResponse{Page: page, Limit: limit}
+
+	total, err := reviews(client).CountDocuments(ctx, filter)
+	if err != nil {
+		return response, err
+	}
+	response.Total = total
+
+	opts.SetSkip((page - 1) * limit).SetLimit(limit)
+	cursor, err := reviews(client).Find(ctx, filter, opts)
+	if err != nil {
+		return response, err
+	}
+
+	response.Reviews = []models.UserReview{}
+	err = cursor.All(ctx, &response.Reviews)
+	return response, err
+}
+
+// requireMovie returns ErrMovieNotFound unless the movie exists and is not
+// deleted.
+func requireMovie(ctx context.Context, client *mongo.Client, imdbID string) error {
+	err := database.OpenCollection("movies", client).FindOne(ctx,
+		bson.M{"imdb_id": imdbID, "deleted_at": nil},
+		options.FindOne().SetProjection(bson.M{"_id": 1}),
+	).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrMovieNotFound
+	}
+	return err
+}
+
+// refreshRating recomputes a movie's average rating and count from its
+// visible reviews. Recomputing rather than adjusting in place keeps the figures
+// right when writes race.
+func refreshRating(ctx context.Context, client *mongo.Client, imdbID string) error {
+	match := visibleReviews()
+	match["imdb_id"] = imdbID
+
+	cursor, err := reviews(client).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     nil,
+			"average": bson.M{"$avg": "$rating"},
+			"count":   bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	var summary []struct {
+		Average float64 `bson:"average"`
+		Count   int64   `bson:"count"`
+	}
+	if err := cursor.All(ctx, &summary); err != nil {
+		return err
+	}
+
+	update := bson.M{"$unset": bson.M{"average_rating": "", "rating_count": ""}}
+	if len(summary) > 0 {
+		update = bson.M{"$set": bson.M{
+			"average_rating": math.Round(summary[0].Average*100) / 100,
+			"rating_count":   summary[0].Count,
+		}}
+	}
+	_, err = database.OpenCollection("movies", client).UpdateOne(ctx, bson.M{"imdb_id": imdbID}, update)
+	return err
+}
+
+// ReportReview records a user's report of someone else's review. Once
+// threshold users have reported it, a published or approved review goes
+// back into the moderation queue.
+func ReportReview(ctx context.Context, client *mongo.Client, reviewID bson.ObjectID, imdbID string, report models.ReviewReport, threshold int) error {
+	report.ReportedAt = time.Now()
+	result, err := reviews(client).UpdateOne(ctx,
+		bson.M{
+			"_id":             reviewID,
+			"imdb_id":         imdbID,
+			"user_id":         bson.M{"$ne": report.UserID},
+			"reports.user_id": bson.M{"$ne": report.UserID},
+		},
+		bson.M{"$push": bson.M{"reports": report}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		review, err := reviewByID(ctx, client, reviewID)
+		switch {
+		case err != nil:
+			return err
+		case review.ImdbID != imdbID:
+			return ErrReviewNotFound
+		case review.UserID == report.UserID:
+			return ErrOwnReview
+		}
+		return ErrAlreadyReported
+	}
+
+	_, err = reviews(client).UpdateOne(ctx,
+		bson.M{
+			"_id":                                  reviewID,
+			fmt.Sprintf("reports.%d", threshold-1): bson.M{"$exists": true},
+			"moderation.status":                    bson.M{"$nin": bson.A{models.ReviewFlagged, models.ReviewHidden, models.ReviewRejected}},
+		},
+		bson.M{"$set": bson.M{"moderation.status": models.ReviewFlagged}},
+	)
+	return err
+}
+
+// ModerationQueue pages through reviews with the given statuses, flagged
+// and hidden by default, oldest first.
+func ModerationQueue(ctx context.Context, client *mongo.Client, statuses []string, page, limit int64) (models.ReviewListResponse, error) {
+	if len(statuses) == 0 {
+		statuses = []string{models.ReviewFlagged, models.ReviewHidden}
+	}
+	filter := bson.M{"moderation.status": bson.M{"$in": statuses}}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	return pageReviews(ctx, client, filter, opts, page, limit)
+}
+
+// ModerateReview records an admin's verdict on a queued review. Approving
+// clears its reports, so only new reports can queue it again. The movie's
+// rating is refreshed, as the review may have become visible or hidden.
+func ModerateReview(ctx context.Context, client *mongo.Client, reviewID bson.ObjectID, approve bool, adminID, note string) (models.UserReview, error) {
+	status := models.ReviewRejected
+	if approve {
+		status = models.ReviewApproved
+	}
+	update := bson.M{"$set": bson.M{
+		"moderation.status":      status,
+		"moderation.reviewed_by": adminID,
+		"moderation.reviewed_at": time.Now(),
+		"moderation.note":        note,
+	}}
+	if approve {
+		update["$unset"] = bson.M{"reports": ""}
+	}
+
+	var review models.UserReview
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := reviews(client).FindOneAndUpdate(ctx,
+		bson.M{"_id": reviewID, "moderation.status": bson.M{"$in": bson.A{models.ReviewFlagged, models.ReviewHidden}}},
+		update,
+		opts,
+	).Decode(&review)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if _, err := reviewByID(ctx, client, reviewID); err != nil {
+			return review, err
+		}
+		return review, ErrNotModerationDue
+	}
+	if err != nil {
+		return review, err
+	}
+
+	return review, refreshRating(ctx, client, review.ImdbID)
+}
+
+func reviewByID(ctx context.Context, client *mongo.Client, reviewID bson.ObjectID) (models.UserReview, error) {
+	var review models.UserReview
+	err := reviews(client).FindOne(ctx, bson.M{"_id": reviewID}).Decode(&review)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return review, ErrReviewNotFound
+	}
+	return review, err
+}
+
+func reviews(client *mongo.Client) *mongo.Collection {
+	return database.OpenCollection("user_reviews", client)
+}
@@ -0,0 +1,263 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrSessionNotFound = errors.New("watch session not found")
+	ErrNotInHistory    = errors.New("movie is not in the watch history")
+)
+
+// RecordProgress stores a progress report from the player. A report
+// without a session id starts a new session. Once a session's position
+// passes threshold, a share of the duration, the movie counts as completed
+// and leaves the continue watching row.
+func RecordProgress(ctx context.Context, client *mongo.Client, userID, imdbID string, report models.PlaybackProgress, threshold float64) (models.WatchHistoryEntry, error) {
+	var entry models.WatchHistoryEntry
+
+	err := database.OpenCollection("movies", client).FindOne(ctx,
+		bson.M{"imdb_id": imdbID, "deleted_at": nil},
+		options.FindOne().SetProjection(bson.M{"_id": 1}),
+	).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return entry, ErrMovieNotFound
+	}
+	if err != nil {
+		return entry, err
+	}
+
+	position := min(report.Position, report.Duration)
+	progress := position / report.Duration
+	reached := progress >= threshold
+	now := time.Now()
+
+	var session models.WatchSession
+	newSession := report.SessionID == ""
+	newlyCompleted := reached
+
+	if newSession {
+		session = models.WatchSession{
+			ID:            bson.NewObjectID(),
+			UserID:        userID,
+			ImdbID:        imdbID,
+			StartPosition: position,
+			Position:      position,
+			Duration:      report.Duration,
+			Completed:     reached,
+			StartedAt:     now,
+			UpdatedAt:     now,
+		}
+		if _, err := watchSessions(client).InsertOne(ctx, session); err != nil {
+			return entry, err
+		}
+	} else {
+		sessionID, err := bson.ObjectIDFromHex(report.SessionID)
+		if err != nil {
+			return entry, ErrSessionNotFound
+		}
+
+		set := bson.M{"position_seconds": position, "duration_seconds": report.Duration, "updated_at": now}
+		if reached {
+			set["completed"] = true
+		}
+		// the session as it was before this report, to tell whether this
+		// is the report that completes it
+		err = watchSessions(client).FindOneAndUpdate(ctx,
+			bson.M{"_id": sessionID, "user_id": userID, "imdb_id": imdbID},
+			bson.M{"$set": set},
+		).Decode(&session)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entry, ErrSessionNotFound
+		}
+		if err != nil {
+			return entry, err
+		}
+		newlyCompleted = reached && !session.Completed
+		// seeking back through the credits does not undo a completion
+		reached = reached || session.Completed
+	}
+
+	set := bson.M{
+		"position_seconds": position,
+		"duration_seconds": report.Duration,
+		"progress":         progress,
+		"completed":        reached,
+		"last_session_id":  session.ID.Hex(),
+		"last_watched_at":  now,
+	}
+	inc := bson.M{}
+	if newSession {
+		inc["session_count"] = 1
+	}
+	if newlyCompleted {
+		set["completed_at"] = now
+		inc["times_completed"] = 1
+	}
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{"first_watched_at": now},
+	}
+	if len(inc) > 0 {
+		update["$inc"] = inc
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err = watchHistory(client).FindOneAndUpdate(ctx, bson.M{"user_id": userID, "imdb_id": imdbID}, update, opts).Decode(&entry)
+	return entry, err
+}
+
+// HistoryEntry returns where the user is in a movie, for the player to
+// resume from.
+func HistoryEntry(ctx context.Context, client *mongo.Client, userID, imdbID string) (models.WatchHistoryEntry, error) {
+	var entry models.WatchHistoryEntry
+	err := watchHistory(client).FindOne(ctx, bson.M{"user_id": userID, "imdb_id": imdbID}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return entry, ErrNotInHistory
+	}
+	return entry, err
+}
+
+// ContinueWatching returns the movies the user started but has not
+// finished, most recently watched first. Deleted movies are left out.
+func ContinueWatching(ctx context.Context, client *mongo.Client, userID string, limit int64) ([]models.WatchHistoryEntry, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "last_watched_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit)
+	cursor, err := watchHistory(client).Find(ctx,
+		bson.M{"user_id": userID, "completed": false, "position_seconds": bson.M{"$gt": 0}},
+		opts,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var entries []models.WatchHistoryEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	if err := attachMovies(ctx, client, entries); err != nil {
+		return nil, err
+	}
+
+	row := []models.WatchHistoryEntry{}
+	for _, entry := range entries {
+		if entry.Movie != nil {
+			row = append(row, entry)
+		}
+	}
+	return row, nil
+}
+
+// WatchHistory pages through everything the user has watched, most
+// recently watched first. Entries for deleted movies stay listed, without
+// a movie, so they can still be removed.
+func WatchHistory(ctx context.Context, client *mongo.Client, userID string, page, limit int64) (models.WatchHistoryResponse, error) {
+	response := models.WatchHistoryResponse{Page: page, Limit: limit}
+	filter := bson.M{"user_id": userID}
+
+	total, err := watchHistory(client).CountDocuments(ctx, filter)
+	if err != nil {
+		return response, err
+	}
+	response.Total = total
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "last_watched_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((page - 1) * limit).
+		SetLimit(limit)
+	cursor, err := watchHistory(client).Find(ctx, filter, opts)
+	if err != nil {
+		return response, err
+	}
+
+	response.Entries = []models.WatchHistoryEntry{}
+	if err := cursor.All(ctx, &response.Entries); err != nil {
+		return response, err
+	}
+	err = attachMovies(ctx, client, response.Entries)
+	return response, err
+}
+
+// DeleteHistoryEntry forgets the user's progress and sessions for a movie.
+func DeleteHistoryEntry(ctx context.Context, client *mongo.Client, userID, imdbID string) error {
+	result, err := watchHistory(client).DeleteOne(ctx, bson.M{"user_id": userID, "imdb_id": imdbID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotInHistory
+	}
+	_, err = watchSessions(client).DeleteMany(ctx, bson.M{"user_id": userID, "imdb_id": imdbID})
+	return err
+}
+
+// ClearHistory forgets everything the user has watched and returns how
+// many movies were removed.
+func ClearHistory(ctx context.Context, client *mongo.Client, userID string) (int64, error) {
+	result, err := watchHistory(client).DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := watchSessions(client).DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return result.DeletedCount, err
+	}
+	return result.DeletedCount, nil
+}
+
+func attachMovies(ctx context.Context, client *mongo.Client, entries []models.WatchHistoryEntry) error {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ImdbID
+	}
+
+	byID, err := moviesByID(ctx, client, ids)
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if movie, ok := byID[entries[i].ImdbID]; ok {
+			entries[i].Movie = &movie
+		}
+	}
+	return nil
+}
+
+// moviesByID loads the movies with the given imdb_ids that are not deleted.
+func moviesByID(ctx context.Context, client *mongo.Client, ids []string) (map[string]models.Movie, error) {
+	byID := map[string]models.Movie{}
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	cursor, err := database.OpenCollection("movies", client).Find(ctx,
+		bson.M{"imdb_id": bson.M{"$in": ids}, "deleted_at": nil},
+		options.Find().SetProjection(bson.M{"embedding": 0}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var found []models.Movie
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	for _, movie := range found {
+		byID[movie.ImdbID] = movie
+	}
+	return byID, nil
+}
+
+func watchHistory(client *mongo.Client) *mongo.Collection {
+	return database.OpenCollection("watch_history", client)
+}
+
+func watchSessions(client *mongo.Client) *mongo.Collection {
+	return database.OpenCollection("watch_sessions", client)
+}
@@ -0,0 +1,221 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MaxWatchlistItems bounds a watchlist so it stays one small document.
+const MaxWatchlistItems = 500
+
+var (
+	ErrAlreadyOnWatchlist = errors.New("movie is already on the watchlist")
+	ErrNotOnWatchlist     = errors.New("movie is not on the watchlist")
+	ErrWatchlistFull      = fmt.Errorf("watchlist cannot hold more than %d movies", MaxWatchlistItems)
+	ErrWatchlistChanged   = errors.New("watchlist changed while it was being reordered")
+	ErrInvalidOrder       = errors.New("invalid watchlist order")
+)
+
+// GetWatchlist returns the user's watchlist, empty if they never added to it.
+func GetWatchlist(ctx context.Context, client *mongo.Client, userID string) (models.Watchlist, error) {
+	watchlist := models.Watchlist{UserID: userID, Items: []models.WatchlistItem{}}
+	err := watchlists(client).FindOne(ctx, bson.M{"user_id": userID}).Decode(&watchlist)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return watchlist, nil
+	}
+	return watchlist, err
+}
+
+// AddToWatchlist appends a movie to the end of the user's watchlist.
+func AddToWatchlist(ctx context.Context, client *mongo.Client, userID, imdbID string) error {
+	err := database.OpenCollection("movies", client).FindOne(ctx,
+		bson.M{"imdb_id": imdbID, "deleted_at": nil},
+		options.FindOne().SetProjection(bson.M{"_id": 1}),
+	).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrMovieNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	// when the movie is already listed, or the list is full, the filter
+	// misses the existing document and the upsert hits the unique user_id
+	_, err = watchlists(client).UpdateOne(ctx,
+		bson.M{
+			"user_id":       userID,
+			"items.imdb_id": bson.M{"$ne": imdbID},
+			fmt.Sprintf("items.%d", MaxWatchlistItems-1): bson.M{"$exists": false},
+		},
+		bson.M{
+			"$push": bson.M{"items": models.WatchlistItem{ImdbID: imdbID, AddedAt: now}},
+			"$set":  bson.M{"updated_at": now},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		watchlist, getErr := GetWatchlist(ctx, client, userID)
+		if getErr != nil {
+			return getErr
+		}
+		for _, item := range watchlist.Items {
+			if item.ImdbID == imdbID {
+				return ErrAlreadyOnWatchlist
+			}
+		}
+		return ErrWatchlistFull
+	}
+	return err
+}
+
+func RemoveFromWatchlist(ctx context.Context, client *mongo.Client, userID, imdbID string) error {
+	result, err := watchlists(client).UpdateOne(ctx,
+		bson.M{"user_id": userID, "items.imdb_id": imdbID},
+		bson.M{
+			"$pull": bson.M{"items": bson.M{"imdb_id": imdbID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotOnWatchlist
+	}
+	return nil
+}
+
+// ReorderWatchlist puts the watchlist in the given order, which must name
+// every movie on it exactly once. Movies deleted since they were added may
+// be left out of the order and are dropped from the list. The write only
+// applies if the list has not changed since it was read. A bad order is
+// reported as ErrInvalidOrder.
+func ReorderWatchlist(ctx context.Context, client *mongo.Client, userID string, order []string) (models.Watchlist, error) {
+	watchlist, err := GetWatchlist(ctx, client, userID)
+	if err != nil {
+		return watchlist, err
+	}
+
+	ids := make([]string, len(watchlist.Items))
+	for i, item := range watchlist.Items {
+		ids[i] = item.ImdbID
+	}
+	live, err := moviesByID(ctx, client, ids)
+	if err != nil {
+		return watchlist, err
+	}
+
+	reordered, err := reorderItems(watchlist.Items, live, order)
+	if err != nil {
+		return watchlist, err
+	}
+
+	now := time.Now()
+	result, err := watchlists(client).UpdateOne(ctx,
+		bson.M{"user_id": userID, "updated_at": watchlist.UpdatedAt},
+		bson.M{"$set": bson.M{"items": reordered, "updated_at": now}},
+	)
+	if err != nil {
+		return watchlist, err
+	}
+	if result.MatchedCount == 0 && len(reordered) > 0 {
+		return watchlist, ErrWatchlistChanged
+	}
+
+	watchlist.Items = reordered
+	watchlist.UpdatedAt = now
+	return watchlist, nil
+}
+
+// reorderItems returns current in the given order, dropping items whose
+// movie is not in live. Those may be left out of order; every other item
+// must be named exactly once.
+func reorderItems(current []models.WatchlistItem, live map[string]models.Movie, order []string) ([]models.WatchlistItem, error) {
+	items := map[string]models.WatchlistItem{}
+	onList := map[string]bool{}
+	for _, item := range current {
+		onList[item.ImdbID] = true
+		if _, ok := live[item.ImdbID]; ok {
+			items[item.ImdbID] = item
+		}
+	}
+
+	reordered := make([]models.WatchlistItem, 0, len(items))
+	listed := map[string]bool{}
+	for _, imdbID := range order {
+		if listed[imdbID] {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrInvalidOrder, imdbID)
+		}
+		listed[imdbID] = true
+
+		item, ok := items[imdbID]
+		if !ok {
+			if onList[imdbID] {
+				// deleted since it was added, pruned here
+				continue
+			}
+			return nil, fmt.Errorf("%w: %s is not on the watchlist", ErrInvalidOrder, imdbID)
+		}
+		reordered = append(reordered, item)
+	}
+	if len(reordered) != len(items) {
+		return nil, fmt.Errorf("%w: order must list all %d movies on the watchlist", ErrInvalidOrder, len(items))
+	}
+	return reordered, nil
+}
+
+// WatchlistMovies returns the movies on the user's watchlist in list
+// order, leaving out any that have since been deleted.
+func WatchlistMovies(ctx context.Context, client *mongo.Client, userID string) ([]models.Movie, error) {
+	watchlist, err := GetWatchlist(ctx, client, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(watchlist.Items))
+	for i, item := range watchlist.Items {
+		ids[i] = item.ImdbID
+	}
+
+	byID, err := moviesByID(ctx, client, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	onWatchlist := true
+	movies := []models.Movie{}
+	for _, id := range ids {
+		if movie, ok := byID[id]; ok {
+			movie.OnWatchlist = &onWatchlist
+			movies = append(movies, movie)
+		}
+	}
+	return movies, nil
+}
+
+// WatchlistIDs returns the set of movies on the user's watchlist.
+func WatchlistIDs(ctx context.Context, client *mongo.Client, userID string) (map[string]bool, error) {
+	watchlist, err := GetWatchlist(ctx, client, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool, len(watchlist.Items))
+	for _, item := range watchlist.Items {
+		ids[item.ImdbID] = true
+	}
+	return ids, nil
+}
+
+func watchlists(client *mongo.Client) *mongo.Collection {
+	return database.OpenCollection("watchlists", client)
+}
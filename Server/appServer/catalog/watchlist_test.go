@@ -0,0 +1,50 @@
+package catalog
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+)
+
+func TestReorderItems(t *testing.T) {
+	current := []models.WatchlistItem{{ImdbID: "tt1"}, {ImdbID: "tt2"}, {ImdbID: "tt3"}}
+	// tt3 has been deleted since it was added
+	live := map[string]models.Movie{"tt1": {ImdbID: "tt1"}, "tt2": {ImdbID: "tt2"}}
+
+	tests := []struct {
+		name    string
+		order   []string
+		want    []string
+		wantErr string
+	}{
+		{name: "reversed", order: []string{"tt2", "tt1"}, want: []string{"tt2", "tt1"}},
+		{name: "deleted movie named", order: []string{"tt3", "tt1", "tt2"}, want: []string{"tt1", "tt2"}},
+		{name: "listed twice", order: []string{"tt1", "tt1", "tt2"}, wantErr: "invalid watchlist order: tt1 is listed twice"},
+		{name: "not on the list", order: []string{"tt1", "tt2", "tt9"}, wantErr: "invalid watchlist order: tt9 is not on the watchlist"},
+		{name: "movie left out", order: []string{"tt2"}, wantErr: "invalid watchlist order: order must list all 2 movies on the watchlist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := reorderItems(current, live, tt.order)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidOrder) || err.Error() != tt.wantErr {
+					t.Fatalf("reorderItems error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("reorderItems error: %v", err)
+			}
+			var got []string
+			for _, item := range items {
+				got = append(got, item.ImdbID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reorderItems order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/catalog"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+)
+
+// runCommand runs the maintenance subcommand named by args[0].
+func runCommand(args []string) error {
+	switch args[0] {
+	case "import":
+		return runImport(args[1:])
+	case "export":
+		return runExport(args[1:])
+	case "restore":
+		return runRestore(args[1:])
+	case "migrate-indexes":
+		return runMigrateIndexes(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "file format, csv or jsonl (default: from the file extension)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: import [-format csv|jsonl] FILE")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("import needs exactly one file")
+	}
+	path := flags.Arg(0)
+
+	if *format == "" {
+		*format = catalog.FormatFromFilename(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	client := database.Connect()
+	defer client.Disconnect(context.Background())
+
+	report, err := catalog.ImportMovies(context.Background(), client, file, *format, "cli")
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if encodeErr := encoder.Encode(report); encodeErr != nil && err == nil {
+		err = encodeErr
+	}
+	return err
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	out := flags.String("out", "", "archive file to write (required)")
+	format := flags.String("format", catalog.FormatJSONL, "record format, jsonl or bson")
+	collections := flags.String("collections", strings.Join(catalog.BackupCollections, ","), "comma separated collections to export")
+	secrets := flags.String("secrets", catalog.SecretsExclude, "user passwords and tokens: exclude, redact or include")
+	flags.Parse(args)
+
+	if *out == "" {
+		flags.Usage()
+		return errors.New("export needs -out")
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	client := database.Connect()
+	defer client.Disconnect(context.Background())
+
+	opts := catalog.ExportOptions{
+		Collections: strings.Split(*collections, ","),
+		Format:      *format,
+		Secrets:     *secrets,
+	}
+	if err := catalog.ExportCollections(context.Background(), client, file, opts); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	format := flags.String("format", "", "record format, jsonl or bson (default: from the file extension)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: restore [-format jsonl|bson] ARCHIVE")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("restore needs exactly one archive")
+	}
+	path := flags.Arg(0)
+
+	if *format == "" {
+		*format = catalog.ArchiveFormatFromFilename(path)
+	}
+	if *format == "" {
+		return errors.New("cannot tell the archive format from its name, pass -format")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	client := database.Connect()
+	defer client.Disconnect(context.Background())
+
+	report, err := catalog.RestoreCollections(context.Background(), client, file, *format)
+	for collection, count := range report {
+		fmt.Printf("%s: %d documents restored\n", collection, count)
+	}
+	return err
+}
+
+func runMigrateIndexes(args []string) error {
+	flags := flag.NewFlagSet("migrate-indexes", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: migrate-indexes")
+		fmt.Fprintln(flags.Output(), "drops and rebuilds indexes whose options changed; resolve duplicate titles first")
+	}
+	flags.Parse(args)
+
+	client := database.Connect()
+	defer client.Disconnect(context.Background())
+
+	return database.RebuildIndexes(client)
+}
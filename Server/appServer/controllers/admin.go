@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// requireAdmin writes an error response and returns false unless the
+// authenticated user is part of the ADMIN role.
+func requireAdmin(c *gin.Context) bool {
+	role, err := utils.GetRoleFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role not found in context"})
+		return false
+	}
+
+	if role != "ADMIN" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user must be part of the ADMIN role"})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/catalog"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func ImportMovies(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		format := c.Query("format")
+
+		// accept either a multipart upload in the "file" field or a raw body
+		var body io.Reader = c.Request.Body
+		if fileHeader, err := c.FormFile("file"); err == nil {
+			file, err := fileHeader.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unable to read uploaded file"})
+				return
+			}
+			defer file.Close()
+			body = file
+
+			if format == "" {
+				format = catalog.FormatFromFilename(fileHeader.Filename)
+			}
+		}
+
+		if format != catalog.FormatCSV && format != catalog.FormatJSONL {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or jsonl"})
+			return
+		}
+
+		adminId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		report, err := catalog.ImportMovies(ctx, client, body, format, adminId)
+		if err != nil {
+			if errors.Is(err, catalog.ErrInvalidImport) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Import failed", "details": err.Error(), "report": report})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Import failed", "details": err.Error(), "report": report})
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+func ExportCatalog(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		opts := catalog.ExportOptions{
+			Format:  c.DefaultQuery("format", catalog.FormatJSONL),
+			Secrets: c.DefaultQuery("secrets", catalog.SecretsExclude),
+		}
+		if collections := c.Query("collections"); collections != "" {
+			opts.Collections = strings.Split(collections, ",")
+		}
+
+		// credentials only leave the server through the export command
+		if opts.Secrets == catalog.SecretsInclude {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "secrets=include is only available from the export command"})
+			return
+		}
+		opts, err := opts.Validate()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export options", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		filename := fmt.Sprintf("magicstream-%s.%s.gz", time.Now().Format("20060102-150405"), opts.Format)
+		c.Header("Content-Type", "application/gzip")
+		c.Header("Content-Disposition", "attachment; filename="+filename)
+		c.Status(http.StatusOK)
+
+		// the archive is streamed, so failures after this point can only be logged
+		if err := catalog.ExportCollections(ctx, client, c.Writer, opts); err != nil {
+			log.Println("Error exporting catalog:", err)
+		}
+	}
+}
+
+func GetDuplicateMovies(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		threshold := 0.85
+		if thresholdStr := c.Query("threshold"); thresholdStr != "" {
+			parsedVal, err := strconv.ParseFloat(thresholdStr, 64)
+			if err != nil || parsedVal <= 0 || parsedVal > 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be a number between 0 and 1"})
+				return
+			}
+			threshold = parsedVal
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		groups, err := catalog.FindDuplicateTitles(ctx, client, threshold)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error finding duplicate movies"})
+			return
+		}
+
+		c.JSON(http.StatusOK, groups)
+	}
+}
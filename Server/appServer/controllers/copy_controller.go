@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/copywriter"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/prompts"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/ranker"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func GetMovieCopy(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var movie models.Movie
+		err := database.OpenCollection("movies", client).FindOne(ctx, bson.M{"imdb_id": c.Param("imdb_id"), "deleted_at": nil}, options.FindOne().SetProjection(bson.M{"embedding": 0})).Decode(&movie)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"synopsis": movie.Synopsis, "tagline": movie.Tagline, "copy": movie.Copy})
+	}
+}
+
+// DraftMovieCopy has the LLM used for ranking write a synopsis and tagline
+// from the movie's title, genres and admin review. The result is kept as a
+// draft; the public fields keep their approved copy until it is approved.
+func DraftMovieCopy(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		adminId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		movieCollection := database.OpenCollection("movies", client)
+
+		var movie models.Movie
+		err = movieCollection.FindOne(ctx, bson.M{"imdb_id": c.Param("imdb_id"), "deleted_at": nil}, options.FindOne().SetProjection(bson.M{"embedding": 0})).Decode(&movie)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		if movie.AdminReview == "" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Movie needs an admin review to draft copy from"})
+			return
+		}
+
+		if err := godotenv.Load(".env"); err != nil {
+			log.Println("Warning: .env file not found")
+		}
+		generator, err := ranker.GeneratorFromEnv()
+		if err != nil {
+			log.Println("Error configuring copy generator:", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "No LLM configured"})
+			return
+		}
+
+		template, err := currentTemplate(ctx, client, prompts.MovieCopy)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching prompt template", "details": err.Error()})
+			return
+		}
+		prompt := renderMoviePrompt(template, movie, nil)
+
+		draft, err := copywriter.Write(ctx, generator, prompt, ranker.DefaultMaxAttempts)
+		if err != nil {
+			log.Println("Error drafting copy:", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Error drafting copy"})
+			return
+		}
+
+		movieCopy := models.MovieCopy{
+			Synopsis:  draft.Synopsis,
+			Tagline:   draft.Tagline,
+			Status:    models.CopyDraft,
+			Model:     draft.Result.Model,
+			Prompt:    prompt,
+			DraftedBy: adminId,
+			DraftedAt: time.Now(),
+		}
+		result, err := movieCollection.UpdateOne(ctx,
+			bson.M{"imdb_id": movie.ImdbID, "deleted_at": nil},
+			bson.M{"$set": bson.M{"copy": movieCopy}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error saving draft", "details": err.Error()})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, movieCopy)
+	}
+}
+
+// ApproveMovieCopy publishes the draft, with any edits the admin made to
+// it, as the movie's synopsis and tagline.
+func ApproveMovieCopy(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		var req struct {
+			Synopsis *string `json:"synopsis" validate:"omitempty,min=1,max=2000"`
+			Tagline  *string `json:"tagline" validate:"omitempty,min=1,max=120"`
+		}
+		// the body is optional; an empty one approves the draft as written
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+				return
+			}
+		}
+		if err := validate.Struct(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		adminId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		movieCollection := database.OpenCollection("movies", client)
+		filter := bson.M{"imdb_id": c.Param("imdb_id"), "deleted_at": nil, "copy.status": models.CopyDraft}
+
+		var movie models.Movie
+		if err := movieCollection.FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{"embedding": 0})).Decode(&movie); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie has no draft copy to approve"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching movie", "details": err.Error()})
+			return
+		}
+
+		synopsis, tagline := movie.Copy.Synopsis, movie.Copy.Tagline
+		if req.Synopsis != nil {
+			synopsis = *req.Synopsis
+		}
+		if req.Tagline != nil {
+			tagline = *req.Tagline
+		}
+
+		// matching the drafted_at guards against approving a draft that was
+		// replaced since it was read
+		filter["copy.drafted_at"] = movie.Copy.DraftedAt
+		now := time.Now()
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"embedding": 0})
+		err = movieCollection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{
+			"synopsis":         synopsis,
+			"tagline":          tagline,
+			"copy.synopsis":    synopsis,
+			"copy.tagline":     tagline,
+			"copy.status":      models.CopyApproved,
+			"copy.approved_by": adminId,
+			"copy.approved_at": now,
+		}}, opts).Decode(&movie)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Draft changed while approving; review it again"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error approving copy", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"synopsis": movie.Synopsis, "tagline": movie.Tagline, "copy": movie.Copy})
+	}
+}
+
+// DiscardMovieCopy drops an unapproved draft. Approved copy is untouched.
+func DiscardMovieCopy(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		result, err := database.OpenCollection("movies", client).UpdateOne(ctx,
+			bson.M{"imdb_id": c.Param("imdb_id"), "deleted_at": nil, "copy.status": models.CopyDraft},
+			bson.M{"$unset": bson.M{"copy": ""}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error discarding draft", "details": err.Error()})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie has no draft copy"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
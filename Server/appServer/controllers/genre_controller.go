@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/catalog"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func CreateGenre(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		var genre models.Genre
+		if err := c.ShouldBindJSON(&genre); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		if genre.GenreID == 0 {
+			nextID, err := catalog.NextGenreID(ctx, client)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error assigning genre id"})
+				return
+			}
+			genre.GenreID = nextID
+		}
+
+		if err := validate.Struct(&genre); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		var genreCollection *mongo.Collection = database.OpenCollection("genres", client)
+
+		if _, err := genreCollection.InsertOne(ctx, genre); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Genre with this id or name already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating genre"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, genre)
+	}
+}
+
+func RenameGenre(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		genreID, err := strconv.Atoi(c.Param("genre_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Genre id must be an integer"})
+			return
+		}
+
+		var req struct {
+			GenreName string `json:"genre_name" validate:"required,min=2,max=100"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		if err := validate.Struct(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		propagation, err := catalog.RenameGenre(ctx, client, genreID, req.GenreName)
+		if err != nil {
+			switch {
+			case errors.Is(err, catalog.ErrGenreNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+			case mongo.IsDuplicateKeyError(err):
+				c.JSON(http.StatusConflict, gin.H{"error": "Genre with this name already exists"})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error renaming genre"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"genre":          models.Genre{GenreID: genreID, GenreName: req.GenreName},
+			"movies_updated": propagation.MoviesUpdated,
+			"users_updated":  propagation.UsersUpdated,
+		})
+	}
+}
+
+func RetireGenre(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		genreID, err := strconv.Atoi(c.Param("genre_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Genre id must be an integer"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		propagation, err := catalog.RetireGenre(ctx, client, genreID)
+		if err != nil {
+			if errors.Is(err, catalog.ErrGenreNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retiring genre"})
+			return
+		}
+
+		c.JSON(http.StatusOK, propagation)
+	}
+}
+
+// canonicalGenres swaps genres for their stored copies. It writes a 400
+// response and returns false when any genre id is unknown.
+func canonicalGenres(c *gin.Context, client *mongo.Client, genres []models.Genre) ([]models.Genre, bool) {
+	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	known, err := catalog.LoadGenres(ctx, client)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching movie genres"})
+		return nil, false
+	}
+
+	canonical, err := catalog.CanonicalGenres(known, genres)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+		return nil, false
+	}
+	return canonical, true
+}
@@ -5,18 +5,20 @@ import (
 	"errors"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/jobs"
 	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/prompts"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/ranker"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/search"
 	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
-	"github.com/tmc/langchaingo/llms/openai"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -29,23 +31,101 @@ func GetMovies(client *mongo.Client) gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		var movies []models.Movie
+		query, err := parseMovieListQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		filter, err := query.pageFilter()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
 		var movieCollection *mongo.Collection = database.OpenCollection("movies", client)
 
-		cursor, err := movieCollection.Find(ctx, bson.M{})
+		total, err := movieCollection.CountDocuments(ctx, query.Filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while counting movies"})
+			return
+		}
 
+		// fetch one extra movie to find out whether there is a next page
+		findOptions := options.Find()
+		findOptions.SetProjection(bson.M{"embedding": 0})
+		findOptions.SetSort(query.sort())
+		findOptions.SetLimit(query.Limit + 1)
+		if query.Cursor == nil {
+			findOptions.SetSkip((query.Page - 1) * query.Limit)
+		}
+
+		cursor, err := movieCollection.Find(ctx, filter, findOptions)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while fetching movies"})
+			return
 		}
 		defer cursor.Close(ctx)
 
+		movies := []models.Movie{}
 		if err = cursor.All(ctx, &movies); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while decoding movies"})
+			return
 		}
 
-		c.JSON(http.StatusOK, movies)
+		response := models.MovieListResponse{
+			Total: total,
+			Limit: query.Limit,
+		}
+		if query.Cursor == nil {
+			response.Page = query.Page
+		}
 
+		if int64(len(movies)) > query.Limit {
+			movies = movies[:query.Limit]
+			nextCursor, err := encodeMovieCursor(movies[len(movies)-1], query.SortField)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while building next cursor"})
+				return
+			}
+			response.NextCursor = nextCursor
+		}
+		response.Movies = movies
+		markWatchlist(ctx, c, client, moviePointers(response.Movies)...)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func SearchMovies(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		query := strings.TrimSpace(c.Query("q"))
+		if query == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+			return
+		}
+
+		limit := defaultMoviePageSize
+		if limitStr := c.Query("limit"); limitStr != "" {
+			parsedVal, err := strconv.ParseInt(limitStr, 10, 64)
+			if err != nil || parsedVal < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+				return
+			}
+			limit = min(parsedVal, maxMoviePageSize)
+		}
+
+		results, err := search.NewMongoSearcher(client).Search(ctx, query, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while searching movies"})
+			return
+		}
+		markWatchlist(ctx, c, client, resultMovies(results)...)
+
+		c.JSON(http.StatusOK, results)
 	}
 }
 
@@ -64,11 +144,17 @@ func GetMovie(client *mongo.Client) gin.HandlerFunc {
 
 		var movieCollection *mongo.Collection = database.OpenCollection("movies", client)
 
-		err := movieCollection.FindOne(ctx, bson.M{"imdb_id": movieID}).Decode(&movie)
+		err := movieCollection.FindOne(ctx, bson.M{"imdb_id": movieID, "deleted_at": nil}, options.FindOne().SetProjection(bson.M{"embedding": 0})).Decode(&movie)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Movie not found"})
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching movie"})
 			return
 		}
+		markWatchlist(ctx, c, client, &movie)
+		recordEvent(ctx, c, client, models.EventView, movie.ImdbID)
 
 		c.JSON(http.StatusOK, movie)
 	}
@@ -89,29 +175,217 @@ func AddMovie(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
+		genres, ok := canonicalGenres(c, client, movie.Genre)
+		if !ok {
+			return
+		}
+		movie.Genre = genres
+
+		// synopsis and tagline are only ever set by approving a draft
+		movie.Synopsis, movie.Tagline = "", ""
+
 		var movieCollection *mongo.Collection = database.OpenCollection("movies", client)
 
-		result, err := movieCollection.InsertOne(ctx, movie)
+		var existingMovie models.Movie
+		err := movieCollection.FindOne(ctx, bson.M{"imdb_id": movie.ImdbID, "deleted_at": nil}, options.FindOne().SetProjection(bson.M{"embedding": 0})).Decode(&existingMovie)
+		if err == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Movie with this imdb_id already exists", "movie": existingMovie})
+			return
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing movie"})
+			return
+		}
+
+		// a soft-deleted movie with the same imdb_id still holds the unique
+		// index, so adding it again replaces the deleted document
+		var saved models.Movie
+		opts := options.FindOneAndReplace().
+			SetUpsert(true).
+			SetReturnDocument(options.After).
+			SetProjection(bson.M{"_id": 1})
+		err = movieCollection.FindOneAndReplace(ctx, bson.M{"imdb_id": movie.ImdbID, "deleted_at": bson.M{"$ne": nil}}, movie, opts).Decode(&saved)
 
 		if err != nil {
+			// lost a race with another insert of the same imdb_id
+			if mongo.IsDuplicateKeyError(err) {
+				movieCollection.FindOne(ctx, bson.M{"imdb_id": movie.ImdbID}, options.FindOne().SetProjection(bson.M{"embedding": 0})).Decode(&existingMovie)
+				c.JSON(http.StatusConflict, gin.H{"error": "Movie with this imdb_id already exists", "movie": existingMovie})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while adding movie"})
 			return
 		}
-		c.JSON(http.StatusCreated, result)
+		c.JSON(http.StatusCreated, gin.H{"InsertedID": saved.ID})
 	}
 }
 
-func AdminReviewUpdate(client *mongo.Client) gin.HandlerFunc {
+func UpdateMovie(client *mongo.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
 
-		role, err := utils.GetRoleFromContext(c)
+		movieId := c.Param("imdb_id")
+		if movieId == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie Id is required"})
+			return
+		}
+
+		var movie models.Movie
+		if err := c.ShouldBindJSON(&movie); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+		if movie.ImdbID != "" && movie.ImdbID != movieId {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "imdb_id cannot be changed"})
+			return
+		}
+		movie.ImdbID = movieId
+
+		if err := validate.StructExcept(&movie, "Ranking"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		genres, ok := canonicalGenres(c, client, movie.Genre)
+		if !ok {
+			return
+		}
+		movie.Genre = genres
+
+		saveMovieFields(c, client, movie)
+	}
+}
+
+func PatchMovie(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		movieId := c.Param("imdb_id")
+		if movieId == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie Id is required"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var movieCollection *mongo.Collection = database.OpenCollection("movies", client)
+
+		var movie models.Movie
+		err := movieCollection.FindOne(ctx, bson.M{"imdb_id": movieId, "deleted_at": nil}, options.FindOne().SetProjection(bson.M{"embedding": 0})).Decode(&movie)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Role not found in context"})
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching movie"})
+			return
+		}
+
+		// binding onto the stored movie only overwrites the fields present in the body
+		if err := c.ShouldBindJSON(&movie); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+		if movie.ImdbID != movieId {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "imdb_id cannot be changed"})
+			return
+		}
+
+		if err := validate.Struct(&movie); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
 			return
 		}
 
-		if role != "ADMIN" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user must be part of the ADMIN role"})
+		genres, ok := canonicalGenres(c, client, movie.Genre)
+		if !ok {
+			return
+		}
+		movie.Genre = genres
+
+		saveMovieFields(c, client, movie)
+	}
+}
+
+// saveMovieFields writes the admin editable fields of movie back to the
+// stored document and responds with the updated movie. The ranking is not
+// one of them: it changes through PUT /movie/:imdb_id/ranking, which honours
+// the lock and records the decision.
+func saveMovieFields(c *gin.Context, client *mongo.Client, movie models.Movie) {
+	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	filter := bson.M{"imdb_id": movie.ImdbID, "deleted_at": nil}
+	update := bson.M{
+		"$set": bson.M{
+			"title":        movie.Title,
+			"poster_path":  movie.PosterPath,
+			"youtube_id":   movie.YoutubeID,
+			"genre":        movie.Genre,
+			"admin_review": movie.AdminReview,
+		},
+	}
+
+	var movieCollection *mongo.Collection = database.OpenCollection("movies", client)
+
+	var updatedMovie models.Movie
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"embedding": 0})
+	err := movieCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedMovie)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating movie"})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedMovie)
+}
+
+func DeleteMovie(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		movieId := c.Param("imdb_id")
+		if movieId == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie Id is required"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var movieCollection *mongo.Collection = database.OpenCollection("movies", client)
+
+		// soft delete: the document stays but every read filters on deleted_at
+		filter := bson.M{"imdb_id": movieId, "deleted_at": nil}
+		update := bson.M{"$set": bson.M{"deleted_at": time.Now()}}
+
+		result, err := movieCollection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting movie"})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Movie deleted"})
+	}
+}
+
+func AdminReviewUpdate(client *mongo.Client, rankingQueue *jobs.RankingQueue) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		if !requireAdmin(c) {
 			return
 		}
 
@@ -126,33 +400,61 @@ func AdminReviewUpdate(client *mongo.Client) gin.HandlerFunc {
 		var resp struct {
 			RankingName string `json:"ranking_name"`
 			AdminReview string `json:"admin_review"`
+			JobID       string `json:"job_id,omitempty"`
+			// the ranking shown is the previous one until the job finishes
+			RankingPending bool `json:"ranking_pending,omitempty"`
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 			return
 		}
-		sentiment, rankVal, err := GetReviewRankings(req.AdminReview, client)
+
+		adminId, err := utils.GetUserIdFromContext(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting review ranking"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
 			return
 		}
 
-		filter := bson.M{"imdb_id": movieId}
-		update := bson.M{
-			"$set": bson.M{
-				"admin_review": req.AdminReview,
-				"ranking": bson.M{
-					"ranking_value": rankVal,
-					"ranking_name":  sentiment,
-				},
-			},
-		}
 		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
 		var movieCollection *mongo.Collection = database.OpenCollection("movies", client)
 
+		var movie models.Movie
+		err = movieCollection.FindOne(ctx, bson.M{"imdb_id": movieId, "deleted_at": nil}, options.FindOne().SetProjection(bson.M{"embedding": 0})).Decode(&movie)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		// a locked ranking stays as the admin set it; only the review changes
+		if movie.RankingLocked {
+			_, err = movieCollection.UpdateOne(ctx,
+				bson.M{"imdb_id": movieId, "deleted_at": nil},
+				bson.M{"$set": bson.M{"admin_review": req.AdminReview}},
+			)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating movie review"})
+				return
+			}
+
+			resp.RankingName = movie.Ranking.RankingName
+			resp.AdminReview = req.AdminReview
+			c.JSON(http.StatusOK, resp)
+			return
+		}
+
+		// the review is saved straight away; the current ranking stays until
+		// the job replaces it
+		filter := bson.M{"imdb_id": movieId, "deleted_at": nil}
+		update := bson.M{
+			"$set": bson.M{
+				"admin_review":    req.AdminReview,
+				"ranking_pending": true,
+			},
+		}
+
 		result, err := movieCollection.UpdateOne(ctx, filter, update)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating movie review"})
@@ -162,63 +464,80 @@ func AdminReviewUpdate(client *mongo.Client) gin.HandlerFunc {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
 			return
 		}
-		resp.RankingName = sentiment
+
+		job, err := rankingQueue.Enqueue(ctx, movieId, req.AdminReview, adminId)
+		if err != nil {
+			movieCollection.UpdateOne(ctx,
+				bson.M{"imdb_id": movieId, "admin_review": req.AdminReview},
+				bson.M{"$unset": bson.M{"ranking_pending": ""}},
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error queueing review ranking"})
+			return
+		}
+
+		resp.RankingName = movie.Ranking.RankingName
 		resp.AdminReview = req.AdminReview
+		resp.JobID = job.ID.Hex()
+		resp.RankingPending = true
 
-		c.JSON(http.StatusOK, resp)
+		c.JSON(http.StatusAccepted, resp)
 	}
 }
 
 func GetReviewRankings(admin_review string, client *mongo.Client) (string, int, error) {
-	rangkings, err := GetRankings(client)
+	decision, err := GetReviewRankingDecision(context.Background(), models.Movie{AdminReview: admin_review}, client)
 	if err != nil {
 		return "", 0, err
 	}
+	return decision.Ranking.RankingName, decision.Ranking.RankingValue, nil
+}
 
-	sentimentDelimited := ""
-	for _, ranking := range rangkings {
-		if ranking.RankingValue != 999 {
-			sentimentDelimited = sentimentDelimited + ranking.RankingName + ","
-		}
+// GetReviewRankingDecision ranks a movie's admin review and returns the
+// ranking together with the prompt, model and raw response behind it.
+// Cancelling ctx abandons the ranker's call.
+func GetReviewRankingDecision(ctx context.Context, movie models.Movie, client *mongo.Client) (models.RankingDecision, error) {
+	rangkings, err := GetRankings(client)
+	if err != nil {
+		return models.RankingDecision{}, err
 	}
 
-	sentimentDelimited = strings.Trim(sentimentDelimited, ",")
-
 	err = godotenv.Load(".env")
 	if err != nil {
 		log.Println("Warning: .env file not found")
 	}
 
-	// OpenAiApiKey := os.Getenv("OPENAI_API_KEY")
-	// if OpenAiApiKey == "" {
-	// 	return "", 0, errors.New("could not read OPENAI_API_KEY")
-	// }
-
-	llm, err := openai.New(
-		openai.WithToken(os.Getenv("GROQ_API_KEY")),
-		openai.WithBaseURL("https://api.groq.com/openai/v1"),
-		openai.WithModel("llama-3.1-8b-instant"),
-	)
+	reviewRanker, err := ranker.FromEnv()
 	if err != nil {
-		return "", 0, err
+		return models.RankingDecision{}, err
 	}
 
-	base_prompt_template := os.Getenv("BASE_PROMPT_TEMPLATE")
-	base_prompt := strings.Replace(base_prompt_template, "{rankings}", sentimentDelimited, 1)
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
 
-	response, err := llm.Call(context.Background(), base_prompt+admin_review)
+	template, err := currentTemplate(ctx, client, prompts.ReviewRanking)
 	if err != nil {
-		return "", 0, err
+		return models.RankingDecision{}, err
 	}
 
-	rankVal := 0
-	for _, ranking := range rangkings {
-		if ranking.RankingName == response {
-			rankVal = ranking.RankingValue
-			break
-		}
+	prompt := renderMoviePrompt(template, movie, rangkings)
+	ranking, result, cached, err := ranker.RankReviewCached(ctx, ranker.CacheFromEnv(client), reviewRanker, ranker.RankRequest{
+		Prompt:   prompt,
+		Review:   movie.AdminReview,
+		Rankings: rangkings,
+	}, template.Version, ranker.DefaultMaxAttempts)
+	if err != nil {
+		return models.RankingDecision{}, err
 	}
-	return response, rankVal, nil
+
+	return models.RankingDecision{
+		Ranking:       models.Ranking{RankingValue: ranking.RankingValue, RankingName: ranking.RankingName},
+		AdminReview:   movie.AdminReview,
+		Prompt:        prompt,
+		PromptVersion: template.Version,
+		Model:         result.Model,
+		RawResponse:   result.Response,
+		Cached:        cached,
+	}, nil
 }
 
 func GetRankings(client *mongo.Client) ([]models.Ranking, error) {
@@ -229,7 +548,7 @@ func GetRankings(client *mongo.Client) ([]models.Ranking, error) {
 
 	var rankingCollection *mongo.Collection = database.OpenCollection("rankings", client)
 
-	cursor, err := rankingCollection.Find(ctx, bson.M{})
+	cursor, err := rankingCollection.Find(ctx, bson.M{"retired": bson.M{"$ne": true}})
 	if err != nil {
 		return nil, err
 	}
@@ -242,59 +561,6 @@ func GetRankings(client *mongo.Client) ([]models.Ranking, error) {
 	return rankings, nil
 }
 
-func GetRecommendedMovies(client *mongo.Client) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userId, err := utils.GetUserIdFromContext(c)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Unauthorized"})
-			return
-		}
-
-		favouriteGenres, err := GetUsersFavouriteGenres(userId, client)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		err = godotenv.Load(".env")
-		if err != nil {
-			log.Println("Warning: .env file not found")
-		}
-		var recommendeMovieLimitVal int64 = 5
-		recommendedMovieLimitStr := os.Getenv("RECOMMENDED_MOVIES_COUNT")
-		if recommendedMovieLimitStr != "" {
-			if parsedVal, err := strconv.ParseInt(recommendedMovieLimitStr, 10, 64); err == nil {
-				recommendeMovieLimitVal = parsedVal
-			}
-		}
-
-		findOptions := options.Find()
-		findOptions.SetSort(bson.D{{Key: "ranking.ranking_value", Value: 1}})
-
-		findOptions.SetLimit(recommendeMovieLimitVal)
-
-		filter := bson.M{"genre.genre_name": bson.M{"$in": favouriteGenres}}
-
-		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
-		defer cancel()
-
-		var movieCollection *mongo.Collection = database.OpenCollection("movies", client)
-
-		cursor, err := movieCollection.Find(ctx, filter, findOptions)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching recommended movies"})
-			return
-		}
-		defer cursor.Close(ctx)
-
-		var recommendedMovies []models.Movie
-		if err := cursor.All(ctx, &recommendedMovies); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusOK, recommendedMovies)
-	}
-}
-
 func GetUsersFavouriteGenres(userId string, client *mongo.Client) ([]string, error) {
 	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 	defer cancel()
@@ -348,7 +614,7 @@ func GetGenres(client *mongo.Client) gin.HandlerFunc {
 
 		var genreCollection *mongo.Collection = database.OpenCollection("genres", client)
 
-		cursor, err := genreCollection.Find(ctx, bson.D{})
+		cursor, err := genreCollection.Find(ctx, bson.M{"retired_at": nil})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching movie genres"})
 			return
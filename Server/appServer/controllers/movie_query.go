@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	defaultMoviePageSize int64 = 20
+	maxMoviePageSize     int64 = 100
+)
+
+// sort keys accepted by GET /movies, mapped to the document field they sort on
+var movieSortFields = map[string]string{
+	"title":   "title",
+	"ranking": "ranking.ranking_value",
+	"imdb_id": "imdb_id",
+}
+
+type movieListQuery struct {
+	Filter    bson.M
+	SortField string
+	SortDir   int
+	Page      int64
+	Limit     int64
+	Cursor    *movieCursor
+}
+
+// movieCursor marks the last movie of a page: the value of the sort field
+// and the _id used as a tie-breaker.
+type movieCursor struct {
+	Value any    `json:"v"`
+	ID    string `json:"id"`
+}
+
+// parsePage reads the page and limit query parameters shared by the paged
+// list endpoints.
+func parsePage(c *gin.Context) (page, limit int64, err error) {
+	page, limit = 1, defaultMoviePageSize
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit < 1 {
+			return 0, 0, errors.New("limit must be a positive integer")
+		}
+		if limit > maxMoviePageSize {
+			limit = maxMoviePageSize
+		}
+	}
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		page, err = strconv.ParseInt(pageStr, 10, 64)
+		if err != nil || page < 1 {
+			return 0, 0, errors.New("page must be a positive integer")
+		}
+	}
+	return page, limit, nil
+}
+
+func parseMovieListQuery(c *gin.Context) (*movieListQuery, error) {
+	query := &movieListQuery{
+		Filter:    bson.M{"deleted_at": nil},
+		SortField: "_id",
+		SortDir:   1,
+	}
+
+	page, limit, err := parsePage(c)
+	if err != nil {
+		return nil, err
+	}
+	query.Page, query.Limit = page, limit
+
+	if sortStr := c.Query("sort"); sortStr != "" {
+		if strings.HasPrefix(sortStr, "-") {
+			query.SortDir = -1
+			sortStr = strings.TrimPrefix(sortStr, "-")
+		}
+		field, ok := movieSortFields[sortStr]
+		if !ok {
+			return nil, errors.New("sort must be one of title, ranking or imdb_id")
+		}
+		query.SortField = field
+	}
+
+	if genres := c.Query("genre"); genres != "" {
+		var genreNames []string
+		for _, name := range strings.Split(genres, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				genreNames = append(genreNames, name)
+			}
+		}
+		if len(genreNames) > 0 {
+			query.Filter["genre.genre_name"] = bson.M{"$in": genreNames}
+		}
+	}
+
+	rankingRange := bson.M{}
+	if minStr := c.Query("min_ranking"); minStr != "" {
+		minVal, err := strconv.Atoi(minStr)
+		if err != nil {
+			return nil, errors.New("min_ranking must be an integer")
+		}
+		rankingRange["$gte"] = minVal
+	}
+	if maxStr := c.Query("max_ranking"); maxStr != "" {
+		maxVal, err := strconv.Atoi(maxStr)
+		if err != nil {
+			return nil, errors.New("max_ranking must be an integer")
+		}
+		rankingRange["$lte"] = maxVal
+	}
+	if len(rankingRange) > 0 {
+		query.Filter["ranking.ranking_value"] = rankingRange
+	}
+
+	// anchored and case sensitive so the title index can bound the scan
+	if title := c.Query("title"); title != "" {
+		query.Filter["title"] = bson.M{"$regex": "^" + regexp.QuoteMeta(title)}
+	}
+
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		cursor, err := decodeMovieCursor(cursorStr, query.SortField)
+		if err != nil {
+			return nil, err
+		}
+		query.Cursor = cursor
+	}
+
+	return query, nil
+}
+
+// pageFilter returns the filter for the requested page; with a cursor it
+// only matches movies positioned after the cursor in the chosen sort order.
+func (q *movieListQuery) pageFilter() (bson.M, error) {
+	if q.Cursor == nil {
+		return q.Filter, nil
+	}
+
+	lastID, err := bson.ObjectIDFromHex(q.Cursor.ID)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	op := "$gt"
+	if q.SortDir < 0 {
+		op = "$lt"
+	}
+
+	var after bson.M
+	if q.SortField == "_id" {
+		after = bson.M{"_id": bson.M{op: lastID}}
+	} else {
+		after = bson.M{"$or": bson.A{
+			bson.M{q.SortField: bson.M{op: q.Cursor.Value}},
+			bson.M{q.SortField: q.Cursor.Value, "_id": bson.M{op: lastID}},
+		}}
+	}
+
+	return bson.M{"$and": bson.A{q.Filter, after}}, nil
+}
+
+func (q *movieListQuery) sort() bson.D {
+	if q.SortField == "_id" {
+		return bson.D{{Key: "_id", Value: q.SortDir}}
+	}
+	return bson.D{{Key: q.SortField, Value: q.SortDir}, {Key: "_id", Value: q.SortDir}}
+}
+
+func encodeMovieCursor(movie models.Movie, sortField string) (string, error) {
+	cursor := movieCursor{ID: movie.ID.Hex()}
+
+	switch sortField {
+	case "title":
+		cursor.Value = movie.Title
+	case "imdb_id":
+		cursor.Value = movie.ImdbID
+	case "ranking.ranking_value":
+		cursor.Value = movie.Ranking.RankingValue
+	}
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeMovieCursor(cursorStr, sortField string) (*movieCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursorStr)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	var cursor movieCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	// JSON numbers come back as float64, ranking values are stored as ints
+	if sortField == "ranking.ranking_value" {
+		value, ok := cursor.Value.(float64)
+		if !ok {
+			return nil, errors.New("cursor does not match sort order")
+		}
+		cursor.Value = int(value)
+	} else if sortField != "_id" {
+		if _, ok := cursor.Value.(string); !ok {
+			return nil, errors.New("cursor does not match sort order")
+		}
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMovieCursorRoundTrip(t *testing.T) {
+	movie := models.Movie{
+		ID:      bson.NewObjectID(),
+		ImdbID:  "tt0078748",
+		Title:   "Alien",
+		Ranking: models.Ranking{RankingValue: 2, RankingName: "Good"},
+	}
+
+	tests := []struct {
+		sortField string
+		want      any
+	}{
+		{"_id", nil},
+		{"title", "Alien"},
+		{"imdb_id", "tt0078748"},
+		{"ranking.ranking_value", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortField, func(t *testing.T) {
+			encoded, err := encodeMovieCursor(movie, tt.sortField)
+			if err != nil {
+				t.Fatalf("encodeMovieCursor error: %v", err)
+			}
+			cursor, err := decodeMovieCursor(encoded, tt.sortField)
+			if err != nil {
+				t.Fatalf("decodeMovieCursor error: %v", err)
+			}
+			if cursor.ID != movie.ID.Hex() || cursor.Value != tt.want {
+				t.Errorf("cursor = %+v, want value %v and id %s", cursor, tt.want, movie.ID.Hex())
+			}
+		})
+	}
+}
+
+func TestDecodeMovieCursorErrors(t *testing.T) {
+	encode := func(json string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+
+	tests := []struct {
+		name      string
+		cursor    string
+		sortField string
+		wantErr   string
+	}{
+		{name: "not base64", cursor: "not a cursor!", sortField: "title", wantErr: "invalid cursor"},
+		{name: "not json", cursor: encode("{"), sortField: "title", wantErr: "invalid cursor"},
+		{name: "number for a title", cursor: encode(`{"v":2,"id":"x"}`), sortField: "title", wantErr: "cursor does not match sort order"},
+		{name: "title for a ranking", cursor: encode(`{"v":"Alien","id":"x"}`), sortField: "ranking.ranking_value", wantErr: "cursor does not match sort order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeMovieCursor(tt.cursor, tt.sortField)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("decodeMovieCursor error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPageFilter(t *testing.T) {
+	lastID := bson.NewObjectID()
+	filter := bson.M{"deleted_at": nil}
+
+	tests := []struct {
+		name    string
+		query   movieListQuery
+		want    bson.M
+		wantErr bool
+	}{
+		{
+			name:  "first page",
+			query: movieListQuery{Filter: filter, SortField: "title", SortDir: 1},
+			want:  filter,
+		},
+		{
+			name:  "by id",
+			query: movieListQuery{Filter: filter, SortField: "_id", SortDir: 1, Cursor: &movieCursor{ID: lastID.Hex()}},
+			want:  bson.M{"$and": bson.A{filter, bson.M{"_id": bson.M{"$gt": lastID}}}},
+		},
+		{
+			name:  "by title descending",
+			query: movieListQuery{Filter: filter, SortField: "title", SortDir: -1, Cursor: &movieCursor{Value: "Alien", ID: lastID.Hex()}},
+			want: bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+				bson.M{"title": bson.M{"$lt": "Alien"}},
+				bson.M{"title": "Alien", "_id": bson.M{"$lt": lastID}},
+			}}}},
+		},
+		{
+			name:    "bad id",
+			query:   movieListQuery{Filter: filter, SortField: "_id", SortDir: 1, Cursor: &movieCursor{ID: "nope"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query.pageFilter()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("pageFilter returned no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pageFilter error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pageFilter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
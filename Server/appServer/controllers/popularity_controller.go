@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/jobs"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/popularity"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// GetTrendingMovies lists the movies whose engagement grew most from the
+// earlier to the later half of the last 24h, or of ?window=7d or 30d,
+// optionally in one genre.
+func GetTrendingMovies(client *mongo.Client) gin.HandlerFunc {
+	return movieFeed(client, models.FeedTrending, "24h")
+}
+
+// GetPopularMovies lists the most engaged with movies, over the last 7d
+// unless ?window=24h or 30d, optionally in one genre.
+func GetPopularMovies(client *mongo.Client) gin.HandlerFunc {
+	return movieFeed(client, models.FeedPopular, "7d")
+}
+
+func movieFeed(client *mongo.Client, kind, defaultWindow string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, limit, err := parsePage(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		feed, err := popularity.Feed(ctx, client, kind, c.DefaultQuery("window", defaultWindow), c.Param("genre"), limit)
+		if err != nil {
+			if errors.Is(err, popularity.ErrUnknownWindow) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching " + kind + " movies", "details": err.Error()})
+			return
+		}
+		markWatchlist(ctx, c, client, moviePointers(feed.Movies)...)
+
+		c.JSON(http.StatusOK, feed)
+	}
+}
+
+// RefreshMovieFeeds rebuilds the trending and popular feeds without
+// waiting for the next scheduled run.
+func RefreshMovieFeeds(aggregator *jobs.PopularityAggregator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		aggregator.Refresh()
+		c.JSON(http.StatusAccepted, gin.H{"status": "refresh requested"})
+	}
+}
+
+// recordEvent counts the signed in caller's engagement with a movie
+// towards the feeds. It is best effort: a failure is only logged.
+func recordEvent(ctx context.Context, c *gin.Context, client *mongo.Client, eventType, imdbID string) {
+	userId, err := utils.GetUserIdFromContext(c)
+	if err != nil {
+		return
+	}
+	if err := popularity.Record(ctx, client, eventType, imdbID, userId); err != nil {
+		log.Printf("Error recording %s event for %s: %v", eventType, imdbID, err)
+	}
+}
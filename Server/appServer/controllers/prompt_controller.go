@@ -0,0 +1,289 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/copywriter"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/prompts"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/ranker"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var promptName = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+// currentTemplate is the newest stored version of a template. Until one
+// has been saved the built in templates fall back to version 0: the
+// BASE_PROMPT_TEMPLATE env var for review ranking and the default copy
+// prompt for synopses.
+func currentTemplate(ctx context.Context, client *mongo.Client, name string) (models.PromptTemplate, error) {
+	template, err := prompts.Current(ctx, client, name)
+	if errors.Is(err, prompts.ErrTemplateNotFound) {
+		switch name {
+		case prompts.ReviewRanking:
+			return models.PromptTemplate{Name: name, Body: os.Getenv("BASE_PROMPT_TEMPLATE")}, nil
+		case prompts.MovieCopy:
+			return models.PromptTemplate{Name: name, Body: copywriter.DefaultTemplate}, nil
+		}
+	}
+	return template, err
+}
+
+func renderMoviePrompt(template models.PromptTemplate, movie models.Movie, rankings []models.Ranking) string {
+	vars := prompts.Vars{Title: movie.Title, Review: movie.AdminReview}
+	for _, ranking := range rankings {
+		if !ranking.Unranked {
+			vars.Rankings = append(vars.Rankings, ranking.RankingName)
+		}
+	}
+	for _, genre := range movie.Genre {
+		vars.Genres = append(vars.Genres, genre.GenreName)
+	}
+	return prompts.Render(template.Body, vars)
+}
+
+func promptTemplateName(c *gin.Context) (string, bool) {
+	name := c.Param("name")
+	if !promptName.MatchString(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Template name must be 1-64 lowercase letters, digits or underscores"})
+		return "", false
+	}
+	return name, true
+}
+
+func GetPromptTemplate(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+		name, ok := promptTemplateName(c)
+		if !ok {
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		template, err := prompts.Current(ctx, client, name)
+		if err != nil {
+			if errors.Is(err, prompts.ErrTemplateNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching prompt template", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+func ListPromptTemplateVersions(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+		name, ok := promptTemplateName(c)
+		if !ok {
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		templates, err := prompts.Versions(ctx, client, name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching prompt template versions", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"placeholders": prompts.Placeholders, "versions": templates})
+	}
+}
+
+func SavePromptTemplate(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+		name, ok := promptTemplateName(c)
+		if !ok {
+			return
+		}
+
+		var template models.PromptTemplate
+		if err := c.ShouldBindJSON(&template); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+		if err := validate.Struct(&template); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+		if err := prompts.Validate(template.Body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		adminId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		saved, err := prompts.Save(ctx, client, models.PromptTemplate{
+			Name:      name,
+			Body:      template.Body,
+			Note:      template.Note,
+			CreatedBy: adminId,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error saving prompt template", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, saved)
+	}
+}
+
+func RollbackPromptTemplate(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+		name, ok := promptTemplateName(c)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Version int `json:"version" validate:"required,min=1"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+		if err := validate.Struct(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		adminId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		saved, err := prompts.Rollback(ctx, client, name, req.Version, adminId)
+		if err != nil {
+			if errors.Is(err, prompts.ErrVersionNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error rolling back prompt template", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, saved)
+	}
+}
+
+// PreviewPromptTemplate renders a template against a stored movie without
+// calling a ranker. The body defaults to the given version, then to the
+// version in use; a review in the request replaces the movie's own.
+func PreviewPromptTemplate(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+		name, ok := promptTemplateName(c)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			ImdbID  string  `json:"imdb_id" validate:"required"`
+			Body    string  `json:"body" validate:"max=10000"`
+			Version int     `json:"version" validate:"min=0"`
+			Review  *string `json:"review"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+		if err := validate.Struct(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var template models.PromptTemplate
+		var err error
+		switch {
+		case req.Body != "":
+			template = models.PromptTemplate{Name: name, Body: req.Body}
+		case req.Version > 0:
+			template, err = prompts.Version(ctx, client, name, req.Version)
+		default:
+			template, err = currentTemplate(ctx, client, name)
+		}
+		if err != nil {
+			if errors.Is(err, prompts.ErrTemplateNotFound) || errors.Is(err, prompts.ErrVersionNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching prompt template", "details": err.Error()})
+			return
+		}
+		if err := prompts.Validate(template.Body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		var movie models.Movie
+		err = database.OpenCollection("movies", client).FindOne(ctx, bson.M{"imdb_id": req.ImdbID, "deleted_at": nil}, options.FindOne().SetProjection(bson.M{"embedding": 0})).Decode(&movie)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		if req.Review != nil {
+			movie.AdminReview = *req.Review
+		}
+
+		rankings, err := GetRankings(client)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching rankings"})
+			return
+		}
+
+		prompt := renderMoviePrompt(template, movie, rankings)
+		response := gin.H{
+			"name":    template.Name,
+			"version": template.Version,
+			"prompt":  prompt,
+		}
+		if name == prompts.ReviewRanking {
+			// what an LLM ranker is actually sent on its first attempt
+			response["full_prompt"] = prompt + ranker.StructuredOutputInstructions(rankings)
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/catalog"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
+func GetRankingHistory(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		limit := int64(defaultHistoryLimit)
+		if raw := c.Query("limit"); raw != "" {
+			parsed, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || parsed < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+				return
+			}
+			limit = min(parsed, maxHistoryLimit)
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		decisions, err := catalog.RankingHistory(ctx, client, c.Param("imdb_id"), limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching ranking history", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, decisions)
+	}
+}
+
+func OverrideMovieRanking(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		var req struct {
+			RankingValue *int   `json:"ranking_value" validate:"required"`
+			Locked       *bool  `json:"locked"`
+			Reason       string `json:"reason" validate:"max=500"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+		if err := validate.Struct(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		adminId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		// an override is locked unless the admin says otherwise, so the
+		// next re-rank does not quietly undo it
+		locked := true
+		if req.Locked != nil {
+			locked = *req.Locked
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		movie, err := catalog.OverrideRanking(ctx, client, c.Param("imdb_id"), *req.RankingValue, locked, req.Reason, adminId)
+		if err != nil {
+			switch {
+			case errors.Is(err, catalog.ErrRankingNotFound):
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ranking_value is not an active ranking"})
+			case errors.Is(err, catalog.ErrMovieNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error overriding ranking", "details": err.Error()})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, movie)
+	}
+}
+
+func SetMovieRankingLock(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		var req struct {
+			Locked *bool `json:"locked" validate:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+		if err := validate.Struct(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		adminId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		movie, err := catalog.SetRankingLock(ctx, client, c.Param("imdb_id"), *req.Locked, adminId)
+		if err != nil {
+			if errors.Is(err, catalog.ErrMovieNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating ranking lock", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, movie)
+	}
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/ranker"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PurgeRankingCache empties the ranker response cache, or only the entries
+// for ?model= and ?prompt_version= when given.
+func PurgeRankingCache(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		var promptVersion *int
+		if raw := c.Query("prompt_version"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "prompt_version must be a non-negative integer"})
+				return
+			}
+			promptVersion = &parsed
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		// the TTL only matters for new entries, so any cache will do here
+		deleted, err := ranker.NewCache(client, 0).Purge(ctx, c.Query("model"), promptVersion)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error purging ranking cache", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+	}
+}
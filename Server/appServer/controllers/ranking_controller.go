@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/catalog"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func ListRankingLevels(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		rankings, err := catalog.ListRankings(ctx, client, c.Query("include_retired") == "true")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching rankings"})
+			return
+		}
+
+		c.JSON(http.StatusOK, rankings)
+	}
+}
+
+func CreateRankingLevel(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		var ranking models.Ranking
+		if err := c.ShouldBindJSON(&ranking); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+		if err := validate.Struct(&ranking); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		if err := catalog.CreateRanking(ctx, client, ranking); err != nil {
+			if errors.Is(err, catalog.ErrRankingConflict) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating ranking"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, ranking)
+	}
+}
+
+func UpdateRankingLevel(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		value, err := strconv.Atoi(c.Param("ranking_value"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ranking value must be an integer"})
+			return
+		}
+
+		var req struct {
+			RankingValue int    `json:"ranking_value"`
+			RankingName  string `json:"ranking_name"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		adminId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		updated := models.Ranking{RankingValue: req.RankingValue, RankingName: req.RankingName}
+		moviesUpdated, err := catalog.UpdateRanking(ctx, client, value, updated, adminId)
+		if err != nil {
+			switch {
+			case errors.Is(err, catalog.ErrRankingNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Ranking not found"})
+			case errors.Is(err, catalog.ErrRankingConflict):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating ranking"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"movies_updated": moviesUpdated})
+	}
+}
+
+func ReorderRankingLevels(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		var req struct {
+			Order []string `json:"order" validate:"required,min=1"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		if err := validate.Struct(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		adminId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		moviesUpdated, err := catalog.ReorderRankings(ctx, client, req.Order, adminId)
+		if err != nil {
+			if errors.Is(err, catalog.ErrInvalidRankingOrder) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reordering rankings"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"movies_updated": moviesUpdated})
+	}
+}
+
+func RetireRankingLevel(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		value, err := strconv.Atoi(c.Param("ranking_value"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ranking value must be an integer"})
+			return
+		}
+
+		replacement := 0
+		if replacementStr := c.Query("replacement"); replacementStr != "" {
+			if replacement, err = strconv.Atoi(replacementStr); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "replacement must be an integer"})
+				return
+			}
+		}
+
+		adminId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		moviesUpdated, err := catalog.RetireRanking(ctx, client, value, replacement, adminId)
+		if err != nil {
+			if errors.Is(err, catalog.ErrRankingNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Ranking not found"})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"movies_updated": moviesUpdated})
+	}
+}
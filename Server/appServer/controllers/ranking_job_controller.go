@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/jobs"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func GetRankingJob(rankingQueue *jobs.RankingQueue) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		jobID, err := bson.ObjectIDFromHex(c.Param("job_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job id"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		job, err := rankingQueue.Get(ctx, jobID)
+		if err != nil {
+			if errors.Is(err, jobs.ErrJobNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching job"})
+			return
+		}
+
+		c.JSON(http.StatusOK, job)
+	}
+}
+
+// StreamRankingJob pushes the job's state as server-sent events until it
+// finishes or the client disconnects.
+func StreamRankingJob(rankingQueue *jobs.RankingQueue) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		jobID, err := bson.ObjectIDFromHex(c.Param("job_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job id"})
+			return
+		}
+
+		// subscribe before reading so no update can slip in between
+		updates, unsubscribe := rankingQueue.Subscribe(jobID)
+		defer unsubscribe()
+
+		job, err := rankingQueue.Get(c.Request.Context(), jobID)
+		if err != nil {
+			if errors.Is(err, jobs.ErrJobNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching job"})
+			return
+		}
+
+		c.SSEvent("status", job)
+		c.Writer.Flush()
+		if job.Finished() {
+			return
+		}
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case job := <-updates:
+				c.SSEvent("status", job)
+				return !job.Finished()
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/jobs"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/recommend"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// GetRecommendedMovies serves the caller's recommendations from the
+// strategy they are assigned. By default the body is a plain list of
+// movies, with the strategy, experiment and exposure id in headers;
+// ?version=2 returns a RecommendationResponse that also explains each
+// movie.
+func GetRecommendedMovies(client *mongo.Client, service *recommend.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		version := c.DefaultQuery("version", "1")
+		if version != "1" && version != "2" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "version must be 1 or 2"})
+			return
+		}
+
+		favouriteGenres, err := GetUsersFavouriteGenres(userId, client)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		err = godotenv.Load(".env")
+		if err != nil {
+			log.Println("Warning: .env file not found")
+		}
+		var recommendeMovieLimitVal int64 = 5
+		recommendedMovieLimitStr := os.Getenv("RECOMMENDED_MOVIES_COUNT")
+		if recommendedMovieLimitStr != "" {
+			if parsedVal, err := strconv.ParseInt(recommendedMovieLimitStr, 10, 64); err == nil && parsedVal > 0 {
+				recommendeMovieLimitVal = parsedVal
+			}
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		req := recommend.Request{
+			UserID:          userId,
+			FavouriteGenres: favouriteGenres,
+			Limit:           recommendeMovieLimitVal,
+		}
+		result, err := service.Recommend(ctx, req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching recommended movies"})
+			return
+		}
+		markWatchlist(ctx, c, client, moviePointers(result.Movies)...)
+
+		c.Header("X-Recommendation-Strategy", result.Strategy)
+		if result.Experiment != "" {
+			c.Header("X-Recommendation-Experiment", result.Experiment)
+		}
+		if result.ExposureID != "" {
+			c.Header("X-Recommendation-Exposure", result.ExposureID)
+		}
+		if version == "1" {
+			c.JSON(http.StatusOK, result.Movies)
+			return
+		}
+
+		explained, err := service.Explain(ctx, req, result.Movies)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error explaining recommended movies", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, models.RecommendationResponse{
+			Version:         2,
+			Strategy:        result.Strategy,
+			Experiment:      result.Experiment,
+			ExposureID:      result.ExposureID,
+			Recommendations: explained,
+		})
+	}
+}
+
+// GetRecommendationConfig shows the registered strategies and how users
+// are assigned to them.
+func GetRecommendationConfig(service *recommend.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		c.JSON(http.StatusOK, service.Config())
+	}
+}
+
+// RefreshRecommendations rebuilds the movie similarities behind
+// recommendations without waiting for the next scheduled run.
+func RefreshRecommendations(builder *jobs.SimilarityBuilder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		builder.Refresh()
+		c.JSON(http.StatusAccepted, gin.H{"status": "refresh requested"})
+	}
+}
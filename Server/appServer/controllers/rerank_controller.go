@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/jobs"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func StartRerank(reranker *jobs.Reranker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		var req struct {
+			DryRun      bool `json:"dry_run"`
+			Concurrency int  `json:"concurrency"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		adminId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		batch, err := reranker.StartBatch(ctx, req.DryRun, req.Concurrency, adminId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error starting re-ranking"})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, batch)
+	}
+}
+
+func GetRerankBatch(reranker *jobs.Reranker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		batchID, ok := rerankBatchID(c)
+		if !ok {
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		batch, err := reranker.Get(ctx, batchID)
+		if err != nil {
+			rerankError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, batch)
+	}
+}
+
+// GetRerankResults returns the old versus new ranking of each movie, one
+// page at a time; pass the last imdb_id seen as "after" for the next page.
+func GetRerankResults(reranker *jobs.Reranker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		batchID, ok := rerankBatchID(c)
+		if !ok {
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		results, err := reranker.Results(ctx, batchID, c.Query("changed_only") == "true", c.Query("after"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching re-ranking results"})
+			return
+		}
+
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+func ResumeRerank(reranker *jobs.Reranker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		batchID, ok := rerankBatchID(c)
+		if !ok {
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		batch, err := reranker.Resume(ctx, batchID)
+		if err != nil {
+			rerankError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, batch)
+	}
+}
+
+func CancelRerank(reranker *jobs.Reranker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		batchID, ok := rerankBatchID(c)
+		if !ok {
+			return
+		}
+
+		if err := reranker.Cancel(batchID); err != nil {
+			rerankError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Re-ranking will stop after the current page"})
+	}
+}
+
+func rerankBatchID(c *gin.Context) (bson.ObjectID, bool) {
+	batchID, err := bson.ObjectIDFromHex(c.Param("batch_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch id"})
+		return batchID, false
+	}
+	return batchID, true
+}
+
+func rerankError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrBatchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Batch not found"})
+	case errors.Is(err, jobs.ErrBatchNotRunning), errors.Is(err, jobs.ErrBatchRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
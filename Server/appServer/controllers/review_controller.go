@@ -0,0 +1,353 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/catalog"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/moderation"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/popularity"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func GetMovieReviews(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit, err := parsePage(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		response, err := catalog.ListReviews(ctx, client, c.Param("imdb_id"), page, limit)
+		if err != nil {
+			if errors.Is(err, catalog.ErrMovieNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching reviews", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func GetMyReview(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		review, err := catalog.UserReviewOf(ctx, client, c.Param("imdb_id"), userId)
+		if err != nil {
+			if errors.Is(err, catalog.ErrReviewNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching review", "details": err.Error()})
+			return
+		}
+
+		// the owner sees the moderation status but not who reported them
+		review.Reports = nil
+		c.JSON(http.StatusOK, review)
+	}
+}
+
+// SaveMyReview submits the signed in user's rating and review of a movie,
+// or replaces the one they submitted before. The text is scored for abuse
+// and spam first; a high score hides the review until an admin approves
+// it, a moderate one queues it while it stays visible.
+func SaveMyReview(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var review models.UserReview
+		if err := c.ShouldBindJSON(&review); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+		review.Body = strings.TrimSpace(review.Body)
+		if err := validate.Struct(&review); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var user models.User
+		err = database.OpenCollection("users", client).FindOne(ctx,
+			bson.M{"user_id": userId},
+			options.FindOne().SetProjection(bson.M{"first_name": 1, "last_name": 1}),
+		).Decode(&user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user", "details": err.Error()})
+			return
+		}
+
+		review.ImdbID = c.Param("imdb_id")
+		review.UserID = userId
+		review.UserName = reviewerName(user)
+
+		existing, err := catalog.UserReviewOf(ctx, client, review.ImdbID, userId)
+		if err != nil && !errors.Is(err, catalog.ErrReviewNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching review", "details": err.Error()})
+			return
+		}
+		previous := existing.Moderation
+		withheld := previous != nil && (previous.Status == models.ReviewHidden || previous.Status == models.ReviewRejected)
+
+		if withheld && existing.Body == review.Body {
+			// resubmitting the same text keeps the verdict, rejected included
+			review.Moderation = previous
+		} else {
+			config := moderation.FromEnv()
+			scored := config.Score(ctx, review.Body)
+			status := config.Status(scored)
+			switch {
+			// a withheld review goes back to the queue instead of publishing
+			// itself by being edited
+			case withheld:
+				status = models.ReviewHidden
+			// reports against the earlier text still stand after an edit
+			case status == models.ReviewPublished && len(existing.Reports) >= config.ReportThreshold:
+				status = models.ReviewFlagged
+			}
+			review.Moderation = &models.ReviewModeration{
+				Status:  status,
+				Score:   scored.Score,
+				Reasons: scored.Reasons,
+				Model:   scored.Model,
+			}
+		}
+
+		saved, created, err := catalog.SaveReview(ctx, client, review)
+		if err != nil {
+			if errors.Is(err, catalog.ErrMovieNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error saving review", "details": err.Error()})
+			return
+		}
+
+		// only reviews other users can see count as engagement
+		if saved.Moderation != nil && (saved.Moderation.Status == models.ReviewPublished || saved.Moderation.Status == models.ReviewApproved) {
+			if err := popularity.RecordRating(ctx, client, saved.ImdbID, userId, saved.Rating); err != nil {
+				log.Printf("Error recording rating event for %s: %v", saved.ImdbID, err)
+			}
+		}
+
+		saved.Reports = nil
+		httpStatus := http.StatusOK
+		if created {
+			httpStatus = http.StatusCreated
+		}
+		c.JSON(httpStatus, saved)
+	}
+}
+
+func DeleteMyReview(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		if err := catalog.DeleteReview(ctx, client, c.Param("imdb_id"), userId); err != nil {
+			if errors.Is(err, catalog.ErrReviewNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting review", "details": err.Error()})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func ReportReview(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		reviewID, err := bson.ObjectIDFromHex(c.Param("review_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review id"})
+			return
+		}
+
+		var report models.ReviewReport
+		// the reason is optional, and so is the body
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&report); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+				return
+			}
+		}
+		if err := validate.Struct(&report); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+		report.UserID = userId
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		err = catalog.ReportReview(ctx, client, reviewID, c.Param("imdb_id"), report, moderation.FromEnv().ReportThreshold)
+		if err != nil {
+			switch {
+			case errors.Is(err, catalog.ErrReviewNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, catalog.ErrOwnReview):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, catalog.ErrAlreadyReported):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reporting review", "details": err.Error()})
+			}
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"status": "reported"})
+	}
+}
+
+func GetModerationQueue(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		page, limit, err := parsePage(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var statuses []string
+		if raw := c.Query("status"); raw != "" {
+			for _, status := range strings.Split(raw, ",") {
+				switch status = strings.TrimSpace(status); status {
+				case models.ReviewPublished, models.ReviewFlagged, models.ReviewHidden, models.ReviewApproved, models.ReviewRejected:
+					statuses = append(statuses, status)
+				default:
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown moderation status " + status})
+					return
+				}
+			}
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		response, err := catalog.ModerationQueue(ctx, client, statuses, page, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching moderation queue", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func ApproveReview(client *mongo.Client) gin.HandlerFunc {
+	return moderateReview(client, true)
+}
+
+func RejectReview(client *mongo.Client) gin.HandlerFunc {
+	return moderateReview(client, false)
+}
+
+func moderateReview(client *mongo.Client, approve bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		reviewID, err := bson.ObjectIDFromHex(c.Param("review_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review id"})
+			return
+		}
+
+		var req struct {
+			Note string `json:"note" validate:"max=500"`
+		}
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+				return
+			}
+		}
+		if err := validate.Struct(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		adminId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		review, err := catalog.ModerateReview(ctx, client, reviewID, approve, adminId, req.Note)
+		if err != nil {
+			switch {
+			case errors.Is(err, catalog.ErrReviewNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, catalog.ErrNotModerationDue):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error moderating review", "details": err.Error()})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, review)
+	}
+}
+
+// reviewerName is how a reviewer is shown to other users: first name and
+// last initial, for example "Jane D.".
+func reviewerName(user models.User) string {
+	name := user.FirstName
+	if last := []rune(user.LastName); len(last) > 0 {
+		name += " " + strings.ToUpper(string(last[0])) + "."
+	}
+	return name
+}
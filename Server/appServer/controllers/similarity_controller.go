@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/embedding"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/jobs"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const defaultSimilarLimit = 10
+
+func similarLimit(c *gin.Context) (int, bool) {
+	limit := defaultSimilarLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return 0, false
+		}
+		limit = min(parsed, int(maxMoviePageSize))
+	}
+	return limit, true
+}
+
+// GetSimilarMovies lists the movies closest to one movie by embedding. A
+// movie the indexer has not reached yet is embedded on the spot.
+func GetSimilarMovies(client *mongo.Client, embedder embedding.Embedder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, ok := similarLimit(c)
+		if !ok {
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var movie models.Movie
+		err := database.OpenCollection("movies", client).FindOne(ctx, bson.M{"imdb_id": c.Param("imdb_id"), "deleted_at": nil}).Decode(&movie)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		vector := movie.Embedding
+		if vector == nil || vector.Model != embedder.Model() || vector.TextHash != embedding.TextHash(embedding.MovieText(movie)) {
+			fresh, err := embedding.EmbedMovie(ctx, embedder, movie)
+			if err != nil {
+				log.Println("Error embedding movie:", err)
+				c.JSON(http.StatusBadGateway, gin.H{"error": "Error embedding movie"})
+				return
+			}
+			vector = &fresh
+		}
+
+		results, err := embedding.Nearest(ctx, client, embedder.Model(), vector.Vector, limit, movie.ImdbID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error finding similar movies", "details": err.Error()})
+			return
+		}
+		markWatchlist(ctx, c, client, resultMovies(results)...)
+
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// SemanticSearchMovies ranks movies by how close their embedding is to a
+// free text description such as "slow burning space mystery".
+func SemanticSearchMovies(client *mongo.Client, embedder embedding.Embedder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := strings.TrimSpace(c.Query("q"))
+		if query == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+			return
+		}
+		limit, ok := similarLimit(c)
+		if !ok {
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		vectors, err := embedder.Embed(ctx, []string{query})
+		if err != nil {
+			// provider errors can carry endpoints and account details
+			log.Println("Error embedding search query:", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Error embedding query"})
+			return
+		}
+
+		results, err := embedding.Nearest(ctx, client, embedder.Model(), vectors[0], limit, "")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while searching movies"})
+			return
+		}
+		markWatchlist(ctx, c, client, resultMovies(results)...)
+
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// RefreshEmbeddings starts an embedding sweep without waiting for the
+// next scheduled one.
+func RefreshEmbeddings(indexer *jobs.EmbeddingIndexer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+
+		indexer.Refresh()
+		c.JSON(http.StatusAccepted, gin.H{"status": "refresh requested"})
+	}
+}
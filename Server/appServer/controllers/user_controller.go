@@ -40,6 +40,12 @@ func RegisterUser(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
+		genres, ok := canonicalGenres(c, client, user.FavouriteGenres)
+		if !ok {
+			return
+		}
+		user.FavouriteGenres = genres
+
 		hashedPassword, err := HashPassword(user.Password)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to hash password"})
@@ -67,6 +73,10 @@ func RegisterUser(client *mongo.Client) gin.HandlerFunc {
 		result, err := userCollection.InsertOne(ctx, user)
 
 		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "User with this email already exists!"})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "User registration failed!"})
 			return
 		}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/catalog"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const defaultCompletionThreshold = 0.9
+
+// completionThreshold is the share of a movie that must be played for it
+// to count as watched, from WATCH_COMPLETION_THRESHOLD.
+func completionThreshold() float64 {
+	if threshold, err := strconv.ParseFloat(os.Getenv("WATCH_COMPLETION_THRESHOLD"), 64); err == nil && threshold > 0 && threshold <= 1 {
+		return threshold
+	}
+	return defaultCompletionThreshold
+}
+
+// ReportPlayback records the player's position in a movie. The response
+// carries the session_id to send with the session's later reports.
+func ReportPlayback(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var report models.PlaybackProgress
+		if err := c.ShouldBindJSON(&report); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+		if err := validate.Struct(&report); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		entry, err := catalog.RecordProgress(ctx, client, userId, c.Param("imdb_id"), report, completionThreshold())
+		if err != nil {
+			switch {
+			case errors.Is(err, catalog.ErrMovieNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			case errors.Is(err, catalog.ErrSessionNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error recording playback", "details": err.Error()})
+			}
+			return
+		}
+
+		if report.SessionID == "" {
+			recordEvent(ctx, c, client, models.EventView, entry.ImdbID)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"session_id": entry.LastSessionID, "entry": entry})
+	}
+}
+
+// GetPlaybackPosition returns where the user left a movie.
+func GetPlaybackPosition(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		entry, err := catalog.HistoryEntry(ctx, client, userId, c.Param("imdb_id"))
+		if err != nil {
+			if errors.Is(err, catalog.ErrNotInHistory) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching playback position", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, entry)
+	}
+}
+
+// GetContinueWatching lists the movies the user started and has not
+// finished, most recent first.
+func GetContinueWatching(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		_, limit, err := parsePage(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		entries, err := catalog.ContinueWatching(ctx, client, userId, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching continue watching", "details": err.Error()})
+			return
+		}
+		markWatchlist(ctx, c, client, historyMovies(entries)...)
+
+		c.JSON(http.StatusOK, entries)
+	}
+}
+
+func GetWatchHistory(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		page, limit, err := parsePage(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		history, err := catalog.WatchHistory(ctx, client, userId, page, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching watch history", "details": err.Error()})
+			return
+		}
+		markWatchlist(ctx, c, client, historyMovies(history.Entries)...)
+
+		c.JSON(http.StatusOK, history)
+	}
+}
+
+func DeleteWatchHistoryEntry(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		if err := catalog.DeleteHistoryEntry(ctx, client, userId, c.Param("imdb_id")); err != nil {
+			if errors.Is(err, catalog.ErrNotInHistory) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting watch history", "details": err.Error()})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func ClearWatchHistory(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		removed, err := catalog.ClearHistory(ctx, client, userId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error clearing watch history", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"removed": removed})
+	}
+}
+
+func historyMovies(entries []models.WatchHistoryEntry) []*models.Movie {
+	var pointers []*models.Movie
+	for _, entry := range entries {
+		if entry.Movie != nil {
+			pointers = append(pointers, entry.Movie)
+		}
+	}
+	return pointers
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/catalog"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func GetWatchlist(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		movies, err := catalog.WatchlistMovies(ctx, client, userId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching watchlist", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, movies)
+	}
+}
+
+func AddToWatchlist(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var req struct {
+			ImdbID string `json:"imdb_id" validate:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+		if err := validate.Struct(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		if err := catalog.AddToWatchlist(ctx, client, userId, req.ImdbID); err != nil {
+			switch {
+			case errors.Is(err, catalog.ErrMovieNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			case errors.Is(err, catalog.ErrAlreadyOnWatchlist), errors.Is(err, catalog.ErrWatchlistFull):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error adding to watchlist", "details": err.Error()})
+			}
+			return
+		}
+
+		recordEvent(ctx, c, client, models.EventWatchlistAdd, req.ImdbID)
+
+		c.JSON(http.StatusCreated, gin.H{"imdb_id": req.ImdbID, "on_watchlist": true})
+	}
+}
+
+func RemoveFromWatchlist(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		if err := catalog.RemoveFromWatchlist(ctx, client, userId, c.Param("imdb_id")); err != nil {
+			if errors.Is(err, catalog.ErrNotOnWatchlist) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error removing from watchlist", "details": err.Error()})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func ReorderWatchlist(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := utils.GetUserIdFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User Id not found in context"})
+			return
+		}
+
+		var req struct {
+			Order []string `json:"order" validate:"required,max=500,dive,required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON provided"})
+			return
+		}
+		if err := validate.Struct(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation Failed", "details": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		watchlist, err := catalog.ReorderWatchlist(ctx, client, userId, req.Order)
+		if err != nil {
+			if errors.Is(err, catalog.ErrWatchlistChanged) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, catalog.ErrInvalidOrder) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reordering watchlist", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, watchlist)
+	}
+}
+
+// markWatchlist sets OnWatchlist on movies for a signed in caller. It is
+// best effort: anonymous callers, or a failed lookup, leave the flag out.
+func markWatchlist(ctx context.Context, c *gin.Context, client *mongo.Client, movies ...*models.Movie) {
+	userId, err := utils.GetUserIdFromContext(c)
+	if err != nil || len(movies) == 0 {
+		return
+	}
+
+	ids, err := catalog.WatchlistIDs(ctx, client, userId)
+	if err != nil {
+		log.Println("Error fetching watchlist for flags:", err)
+		return
+	}
+
+	for _, movie := range movies {
+		onWatchlist := ids[movie.ImdbID]
+		movie.OnWatchlist = &onWatchlist
+	}
+}
+
+func moviePointers(movies []models.Movie) []*models.Movie {
+	pointers := make([]*models.Movie, len(movies))
+	for i := range movies {
+		pointers[i] = &movies[i]
+	}
+	return pointers
+}
+
+func resultMovies(results []models.MovieSearchResult) []*models.Movie {
+	pointers := make([]*models.Movie, len(results))
+	for i := range results {
+		pointers[i] = &results[i].Movie
+	}
+	return pointers
+}
@@ -0,0 +1,92 @@
+package copywriter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/ranker"
+)
+
+const (
+	maxSynopsisLength = 2000
+	maxTaglineLength  = 120
+)
+
+// DefaultTemplate drafts copy until a movie_copy template has been saved.
+const DefaultTemplate = `Write marketing copy for the movie "{title}" ({genres}).
+Base it on the following review, but do not quote it or mention a reviewer:
+
+{review}
+
+Respond with only a JSON object of the form {"synopsis": "<two to four sentences, no spoilers>", "tagline": "<one line of at most twelve words>"}. Do not add any explanation.`
+
+var ErrUnusableDraft = errors.New("generated copy is not a usable synopsis and tagline")
+
+var jsonObject = regexp.MustCompile(`(?s)\{.*\}`)
+
+// Draft is a generated synopsis and tagline.
+type Draft struct {
+	Synopsis string
+	Tagline  string
+	Result   ranker.RankResult
+}
+
+// Write asks generator for a synopsis and tagline, re-prompting up to
+// maxAttempts times when the answer is not the JSON object asked for.
+func Write(ctx context.Context, generator ranker.Generator, prompt string, maxAttempts int) (Draft, error) {
+	attemptPrompt := prompt
+
+	var result ranker.RankResult
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var err error
+		result, err = generator.Generate(ctx, attemptPrompt)
+		if err != nil {
+			return Draft{}, err
+		}
+
+		draft, err := Parse(result.Response)
+		if err == nil {
+			draft.Result = result
+			return draft, nil
+		}
+
+		attemptPrompt = prompt + fmt.Sprintf(
+			"\n\nYour previous reply could not be used: %v. Reply again with only the JSON object.", err,
+		)
+	}
+
+	return Draft{}, fmt.Errorf("%w after %d attempts", ErrUnusableDraft, maxAttempts)
+}
+
+// Parse reads the synopsis and tagline from a JSON object in response.
+func Parse(response string) (Draft, error) {
+	match := jsonObject.FindString(response)
+	if match == "" {
+		return Draft{}, errors.New("no JSON object in reply")
+	}
+
+	var draft struct {
+		Synopsis string `json:"synopsis"`
+		Tagline  string `json:"tagline"`
+	}
+	if err := json.Unmarshal([]byte(match), &draft); err != nil {
+		return Draft{}, err
+	}
+
+	synopsis := strings.TrimSpace(draft.Synopsis)
+	tagline := strings.Trim(strings.TrimSpace(draft.Tagline), `"`)
+	switch {
+	case synopsis == "" || tagline == "":
+		return Draft{}, errors.New("synopsis and tagline are both required")
+	case utf8.RuneCountInString(synopsis) > maxSynopsisLength:
+		return Draft{}, fmt.Errorf("synopsis is longer than %d characters", maxSynopsisLength)
+	case utf8.RuneCountInString(tagline) > maxTaglineLength:
+		return Draft{}, fmt.Errorf("tagline is longer than %d characters", maxTaglineLength)
+	}
+	return Draft{Synopsis: synopsis, Tagline: tagline}, nil
+}
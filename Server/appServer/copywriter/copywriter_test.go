@@ -0,0 +1,105 @@
+package copywriter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/ranker"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     string
+		wantSynopsis string
+		wantTagline  string
+		wantErr      string
+	}{
+		{
+			name:         "plain object",
+			response:     `{"synopsis": "A crew meets something awful.", "tagline": "In space no one can hear you scream."}`,
+			wantSynopsis: "A crew meets something awful.",
+			wantTagline:  "In space no one can hear you scream.",
+		},
+		{
+			name:         "wrapped in prose and quotes trimmed",
+			response:     "Sure! Here it is:\n```json\n{\"synopsis\": \"  A crew meets something awful. \", \"tagline\": \"\\\"Run.\\\"\"}\n```",
+			wantSynopsis: "A crew meets something awful.",
+			wantTagline:  "Run.",
+		},
+		{name: "no object", response: "I cannot help with that.", wantErr: "no JSON object in reply"},
+		{name: "broken json", response: `{"synopsis": "A crew", }`, wantErr: "invalid character"},
+		{name: "missing tagline", response: `{"synopsis": "A crew meets something awful."}`, wantErr: "synopsis and tagline are both required"},
+		{
+			name:     "tagline too long",
+			response: `{"synopsis": "A crew.", "tagline": "` + strings.Repeat("a", maxTaglineLength+1) + `"}`,
+			wantErr:  "tagline is longer than 120 characters",
+		},
+		{
+			name:     "synopsis too long",
+			response: `{"synopsis": "` + strings.Repeat("é", maxSynopsisLength+1) + `", "tagline": "Run."}`,
+			wantErr:  "synopsis is longer than 2000 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			draft, err := Parse(tt.response)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if draft.Synopsis != tt.wantSynopsis || draft.Tagline != tt.wantTagline {
+				t.Errorf("Parse = %q / %q, want %q / %q", draft.Synopsis, draft.Tagline, tt.wantSynopsis, tt.wantTagline)
+			}
+		})
+	}
+}
+
+// scriptedGenerator answers each prompt with the next canned response.
+type scriptedGenerator struct {
+	responses []string
+	prompts   []string
+}
+
+func (g *scriptedGenerator) Model() string { return "scripted" }
+
+func (g *scriptedGenerator) Generate(ctx context.Context, prompt string) (ranker.RankResult, error) {
+	g.prompts = append(g.prompts, prompt)
+	response := g.responses[len(g.prompts)-1]
+	return ranker.RankResult{Response: response, Model: g.Model()}, nil
+}
+
+func TestWriteReprompts(t *testing.T) {
+	generator := &scriptedGenerator{responses: []string{
+		"no json here",
+		`{"synopsis": "A crew meets something awful.", "tagline": "Run."}`,
+	}}
+
+	draft, err := Write(context.Background(), generator, "Write copy.", 3)
+	if err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if draft.Tagline != "Run." || draft.Result.Model != "scripted" {
+		t.Errorf("Write = %+v", draft)
+	}
+	if len(generator.prompts) != 2 || !strings.Contains(generator.prompts[1], "no JSON object in reply") {
+		t.Errorf("second prompt does not explain the failure: %q", generator.prompts)
+	}
+}
+
+func TestWriteGivesUp(t *testing.T) {
+	generator := &scriptedGenerator{responses: []string{"nope", "still nope"}}
+
+	_, err := Write(context.Background(), generator, "Write copy.", 2)
+	if !errors.Is(err, ErrUnusableDraft) {
+		t.Fatalf("Write error = %v, want ErrUnusableDraft", err)
+	}
+}
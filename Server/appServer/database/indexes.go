@@ -0,0 +1,285 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type indexSpec struct {
+	Name    string
+	Keys    bson.D
+	Options *options.IndexOptionsBuilder
+}
+
+// indexes the server relies on, keyed by collection name
+var collectionIndexes = map[string][]indexSpec{
+	"movies": {
+		{
+			Name:    "imdb_id_unique",
+			Keys:    bson.D{{Key: "imdb_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Name: "title_1__id_1",
+			Keys: bson.D{{Key: "title", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Name: "ranking_value_1__id_1",
+			Keys: bson.D{{Key: "ranking.ranking_value", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Name: "genre_name_1",
+			Keys: bson.D{{Key: "genre.genre_name", Value: 1}},
+		},
+		{
+			Name: "embedding.model_1",
+			Keys: bson.D{{Key: "embedding.model", Value: 1}},
+		},
+		{
+			Name: "movie_text",
+			Keys: bson.D{
+				{Key: "title", Value: "text"},
+				{Key: "genre.genre_name", Value: "text"},
+				{Key: "admin_review", Value: "text"},
+			},
+			Options: options.Index().SetWeights(bson.D{
+				{Key: "title", Value: 10},
+				{Key: "genre.genre_name", Value: 5},
+				{Key: "admin_review", Value: 1},
+			}),
+		},
+	},
+	"genres": {
+		{
+			Name:    "genre_id_unique",
+			Keys:    bson.D{{Key: "genre_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Name:    "genre_name_unique",
+			Keys:    bson.D{{Key: "genre_name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	},
+	"ranking_jobs": {
+		{
+			Name: "status_1_next_attempt_at_1",
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+		},
+		{
+			Name: "imdb_id_1_status_1",
+			Keys: bson.D{{Key: "imdb_id", Value: 1}, {Key: "status", Value: 1}},
+		},
+	},
+	"prompt_templates": {
+		{
+			Name:    "name_1_version_1",
+			Keys:    bson.D{{Key: "name", Value: 1}, {Key: "version", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	},
+	"ranking_cache": {
+		{
+			Name:    "expires_at_ttl",
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	},
+	"movie_events": {
+		// kept for the longest feed window, popularity.EventRetention
+		{
+			Name:    "occurred_at_ttl",
+			Keys:    bson.D{{Key: "occurred_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32((30 * 24 * time.Hour).Seconds())),
+		},
+	},
+	"movie_feeds": {
+		{
+			Name: "computed_at_1",
+			Keys: bson.D{{Key: "computed_at", Value: 1}},
+		},
+	},
+	"movie_similarities": {
+		{
+			Name: "computed_at_1",
+			Keys: bson.D{{Key: "computed_at", Value: 1}},
+		},
+	},
+	"ranking_decisions": {
+		{
+			Name: "imdb_id_1_created_at_-1",
+			Keys: bson.D{{Key: "imdb_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	},
+	"recommendation_exposures": {
+		{
+			Name: "experiment_1_served_at_1",
+			Keys: bson.D{{Key: "experiment", Value: 1}, {Key: "served_at", Value: 1}},
+		},
+		{
+			Name: "user_id_1_served_at_-1",
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "served_at", Value: -1}},
+		},
+	},
+	"rerank_results": {
+		{
+			Name:    "batch_id_1_imdb_id_1",
+			Keys:    bson.D{{Key: "batch_id", Value: 1}, {Key: "imdb_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	},
+	"user_reviews": {
+		{
+			Name:    "imdb_id_1_user_id_1",
+			Keys:    bson.D{{Key: "imdb_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Name: "imdb_id_1_created_at_-1__id_-1",
+			Keys: bson.D{{Key: "imdb_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			Name: "moderation.status_1_created_at_1",
+			Keys: bson.D{{Key: "moderation.status", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+	},
+	"watchlists": {
+		{
+			Name:    "user_id_unique",
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	},
+	"watch_history": {
+		{
+			Name:    "user_id_1_imdb_id_1",
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "imdb_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Name: "user_id_1_last_watched_at_-1",
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_watched_at", Value: -1}},
+		},
+	},
+	"watch_sessions": {
+		{
+			Name: "user_id_1_imdb_id_1",
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "imdb_id", Value: 1}},
+		},
+	},
+	"users": {
+		{
+			Name:    "email_unique",
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Name:    "user_id_unique",
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	},
+}
+
+// server error codes for an index that exists with other options or keys
+const (
+	indexOptionsConflict  = 85
+	indexKeySpecsConflict = 86
+)
+
+// ErrIndexConflict is returned for an index that already exists with the
+// same name or keys but different options. EnsureIndexes leaves it alone;
+// RebuildIndexes replaces it.
+var ErrIndexConflict = errors.New("existing index conflicts with the declared one")
+
+// EnsureIndexes creates every index in collectionIndexes. It never drops an
+// index: one that exists with other options is logged and skipped until it
+// is rebuilt with RebuildIndexes. Indexes that cannot be built, for example
+// a unique index over data that already holds duplicates, are logged and
+// reported in the returned error without stopping the remaining ones.
+func EnsureIndexes(client *mongo.Client) error {
+	return ensureIndexes(client, false)
+}
+
+// RebuildIndexes is EnsureIndexes for an explicit migration: an existing
+// index that conflicts with the declared one is dropped and built again.
+// Resolve duplicates reported by catalog.FindDuplicateTitles first, or the
+// unique indexes will fail to build after the old ones are gone.
+func RebuildIndexes(client *mongo.Client) error {
+	return ensureIndexes(client, true)
+}
+
+func ensureIndexes(client *mongo.Client, rebuild bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	var errs []error
+	for collectionName, specs := range collectionIndexes {
+		collection := OpenCollection(collectionName, client)
+
+		for _, spec := range specs {
+			if err := ensureIndex(ctx, collection, spec, rebuild); err != nil {
+				log.Printf("Index %s on %s: %v", spec.Name, collectionName, err)
+				errs = append(errs, fmt.Errorf("index %s on %s: %w", spec.Name, collectionName, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func ensureIndex(ctx context.Context, collection *mongo.Collection, spec indexSpec, rebuild bool) error {
+	indexOptions := spec.Options
+	if indexOptions == nil {
+		indexOptions = options.Index()
+	}
+	model := mongo.IndexModel{Keys: spec.Keys, Options: indexOptions.SetName(spec.Name)}
+
+	_, err := collection.Indexes().CreateOne(ctx, model)
+
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) || (cmdErr.Code != indexOptionsConflict && cmdErr.Code != indexKeySpecsConflict) {
+		return err
+	}
+	if !rebuild {
+		return fmt.Errorf("%w, run the migrate-indexes command to rebuild it: %v", ErrIndexConflict, err)
+	}
+
+	if err := dropConflictingIndexes(ctx, collection, spec); err != nil {
+		return err
+	}
+	_, err = collection.Indexes().CreateOne(ctx, model)
+	return err
+}
+func dropConflictingIndexes(ctx context.Context, collection *mongo.Collection, spec indexSpec) error {
+	keys, err := bson.Marshal(spec.Keys)
+	if err != nil {
+		return err
+	}
+
+	existing, err := collection.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, index := range existing {
+		if index.Name == "_id_" {
+			continue
+		}
+		if index.Name == spec.Name || bytes.Equal(index.KeysDocument, keys) {
+			log.Printf("Dropping index %s on %s to rebuild it as %s", index.Name, collection.Name(), spec.Name)
+			if err := collection.Indexes().DropOne(ctx, index.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package embedding
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/tmc/langchaingo/llms/ollama"
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+const (
+	ProviderHash   = "hash"
+	ProviderOpenAI = "openai"
+	ProviderOllama = "ollama"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = "text-embedding-3-small"
+	defaultOllamaURL     = "http://localhost:11434"
+	defaultOllamaModel   = "nomic-embed-text"
+)
+
+// Embedder turns texts into vectors. Vectors from different models live in
+// different spaces, so they are only ever compared within one Model.
+type Embedder interface {
+	// Model identifies the provider and model, for example "ollama/nomic-embed-text".
+	Model() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// FromEnv builds the embedder selected by EMBEDDING_PROVIDER (hash, openai
+// or ollama; hash by default). A provider that cannot be configured falls
+// back to the local hashing embedder, so similarity works offline.
+func FromEnv() Embedder {
+	provider := strings.ToLower(os.Getenv("EMBEDDING_PROVIDER"))
+
+	var embedder Embedder
+	var err error
+	switch provider {
+	case "", ProviderHash:
+		return NewHash(defaultHashDimensions)
+	case ProviderOpenAI:
+		embedder, err = NewOpenAI(
+			envOr("EMBEDDING_BASE_URL", defaultOpenAIBaseURL),
+			os.Getenv("EMBEDDING_API_KEY"),
+			envOr("EMBEDDING_MODEL", defaultOpenAIModel),
+		)
+	case ProviderOllama:
+		embedder, err = NewOllama(
+			envOr("OLLAMA_SERVER_URL", defaultOllamaURL),
+			envOr("EMBEDDING_MODEL", defaultOllamaModel),
+		)
+	default:
+		err = fmt.Errorf("unknown EMBEDDING_PROVIDER %q", provider)
+	}
+	if err != nil {
+		log.Printf("Falling back to hashing embeddings: %v", err)
+		return NewHash(defaultHashDimensions)
+	}
+	return embedder
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+type llmEmbedder struct {
+	create func(ctx context.Context, texts []string) ([][]float32, error)
+	model  string
+}
+
+// NewOpenAI returns an embedder for any OpenAI compatible embeddings endpoint.
+func NewOpenAI(baseURL, token, model string) (Embedder, error) {
+	if token == "" {
+		return nil, fmt.Errorf("no EMBEDDING_API_KEY configured for the OpenAI compatible embedder")
+	}
+
+	llm, err := openai.New(
+		openai.WithToken(token),
+		openai.WithBaseURL(baseURL),
+		openai.WithEmbeddingModel(model),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &llmEmbedder{create: llm.CreateEmbedding, model: ProviderOpenAI + "/" + model}, nil
+}
+
+// NewOllama returns an embedder for a local Ollama server.
+func NewOllama(serverURL, model string) (Embedder, error) {
+	llm, err := ollama.New(
+		ollama.WithServerURL(serverURL),
+		ollama.WithModel(model),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &llmEmbedder{create: llm.CreateEmbedding, model: ProviderOllama + "/" + model}, nil
+}
+
+func (e *llmEmbedder) Model() string {
+	return e.model
+}
+
+func (e *llmEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors, err := e.create(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("%s returned %d embeddings for %d texts", e.model, len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+// MovieText is the text a movie is embedded from: its title, genres and
+// admin review.
+func MovieText(movie models.Movie) string {
+	var genres []string
+	for _, genre := range movie.Genre {
+		genres = append(genres, genre.GenreName)
+	}
+
+	parts := []string{movie.Title}
+	if len(genres) > 0 {
+		parts = append(parts, "Genres: "+strings.Join(genres, ", "))
+	}
+	if movie.AdminReview != "" {
+		parts = append(parts, movie.AdminReview)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// TextHash identifies the text a vector was computed from, so changed
+// movies can be found and re-embedded.
+func TextHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Cosine returns the cosine similarity of a and b, or 0 when their
+// lengths differ or either is all zeros.
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
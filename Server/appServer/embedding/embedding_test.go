@@ -0,0 +1,73 @@
+package embedding
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"identical", []float32{1, 2, 3}, []float32{1, 2, 3}, 1},
+		{"scaled", []float32{1, 2, 3}, []float32{2, 4, 6}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"opposite", []float32{1, -1}, []float32{-1, 1}, -1},
+		{"different lengths", []float32{1, 2}, []float32{1, 2, 3}, 0},
+		{"zero vector", []float32{0, 0}, []float32{1, 1}, 0},
+		{"empty", nil, nil, 0},
+	}
+
+	for _, tt := range tests {
+		if got := Cosine(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Cosine %s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHashEmbedder(t *testing.T) {
+	embedder := NewHash(defaultHashDimensions)
+	if got, want := embedder.Model(), "hash/512"; got != want {
+		t.Errorf("Model() = %q, want %q", got, want)
+	}
+
+	texts := []string{
+		"Alien\nGenres: Horror, Sci-Fi\nA crew is hunted by a creature aboard their ship.",
+		"Aliens\nGenres: Horror, Sci-Fi\nA crew of marines is hunted by creatures.",
+		"Notting Hill\nGenres: Comedy, Romance\nA bookshop owner falls for a film star.",
+		"",
+		"the and of",
+	}
+	vectors, err := embedder.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed error: %v", err)
+	}
+	if len(vectors) != len(texts) {
+		t.Fatalf("Embed returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	for i, vector := range vectors {
+		if len(vector) != defaultHashDimensions {
+			t.Fatalf("vector %d has %d dimensions, want %d", i, len(vector), defaultHashDimensions)
+		}
+	}
+
+	again, _ := embedder.Embed(context.Background(), texts[:1])
+	if Cosine(vectors[0], again[0]) < 1-1e-6 {
+		t.Error("embedding the same text twice gave different vectors")
+	}
+	if norm := Cosine(vectors[0], vectors[0]); math.Abs(norm-1) > 1e-6 {
+		t.Errorf("self similarity = %v, want 1", norm)
+	}
+	if related, unrelated := Cosine(vectors[0], vectors[1]), Cosine(vectors[0], vectors[2]); related <= unrelated {
+		t.Errorf("related similarity %v is not above unrelated %v", related, unrelated)
+	}
+	// texts with no words left after stop words embed to the zero vector
+	for _, i := range []int{3, 4} {
+		if Cosine(vectors[i], vectors[0]) != 0 {
+			t.Errorf("text %q did not embed to the zero vector", texts[i])
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/search"
+)
+
+const defaultHashDimensions = 512
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "for": true, "from": true, "has": true, "in": true, "is": true,
+	"it": true, "its": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "was": true, "with": true,
+}
+
+type hashEmbedder struct {
+	dimensions int
+}
+
+// NewHash returns a local embedder that hashes words and word pairs into a
+// fixed number of dimensions. It captures shared vocabulary rather than
+// meaning, but needs no model or network access.
+func NewHash(dimensions int) Embedder {
+	return hashEmbedder{dimensions: dimensions}
+}
+
+func (e hashEmbedder) Model() string {
+	return fmt.Sprintf("%s/%d", ProviderHash, e.dimensions)
+}
+
+func (e hashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e hashEmbedder) embed(text string) []float32 {
+	var words []string
+	for _, word := range search.Tokenize(text) {
+		if !stopWords[word] {
+			words = append(words, word)
+		}
+	}
+
+	counts := map[string]float64{}
+	for i, word := range words {
+		counts[word]++
+		if i > 0 {
+			// pairs weigh half as much as single words
+			counts[words[i-1]+" "+word] += 0.5
+		}
+	}
+
+	vector := make([]float32, e.dimensions)
+	for feature, count := range counts {
+		hash := fnv.New64a()
+		hash.Write([]byte(feature))
+		sum := hash.Sum64()
+
+		// the top bit picks a sign so that collisions tend to cancel out
+		weight := 1 + math.Log(count)
+		if sum>>63 == 1 {
+			weight = -weight
+		}
+		vector[sum%uint64(e.dimensions)] += float32(weight)
+	}
+
+	var norm float64
+	for _, value := range vector {
+		norm += float64(value) * float64(value)
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vector {
+			vector[i] = float32(float64(vector[i]) / norm)
+		}
+	}
+	return vector
+}
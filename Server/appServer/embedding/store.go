@@ -0,0 +1,101 @@
+package embedding
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Nearest returns the limit movies whose vectors from model are closest to
+// vector, best first, leaving out excludeImdbID. It scores every embedded
+// movie in memory, which is plenty for a catalog of this size; the full
+// documents are only loaded for the winners.
+func Nearest(ctx context.Context, client *mongo.Client, model string, vector []float32, limit int, excludeImdbID string) ([]models.MovieSearchResult, error) {
+	movies := database.OpenCollection("movies", client)
+
+	filter := bson.M{"deleted_at": nil, "embedding.model": model}
+	if excludeImdbID != "" {
+		filter["imdb_id"] = bson.M{"$ne": excludeImdbID}
+	}
+	opts := options.Find().SetProjection(bson.M{"imdb_id": 1, "embedding.vector": 1})
+
+	cursor, err := movies.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	type scored struct {
+		imdbID string
+		score  float64
+	}
+	var candidates []scored
+	for cursor.Next(ctx) {
+		var movie models.Movie
+		if err := cursor.Decode(&movie); err != nil {
+			return nil, err
+		}
+		if score := Cosine(vector, movie.Embedding.Vector); score > 0 {
+			candidates = append(candidates, scored{movie.ImdbID, score})
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].imdbID < candidates[j].imdbID
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	ids := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		ids[i] = candidate.imdbID
+	}
+	cursor, err = movies.Find(ctx, bson.M{"imdb_id": bson.M{"$in": ids}, "deleted_at": nil}, options.Find().SetProjection(bson.M{"embedding": 0}))
+	if err != nil {
+		return nil, err
+	}
+	var found []models.Movie
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	byID := map[string]models.Movie{}
+	for _, movie := range found {
+		byID[movie.ImdbID] = movie
+	}
+
+	results := []models.MovieSearchResult{}
+	for _, candidate := range candidates {
+		if movie, ok := byID[candidate.imdbID]; ok {
+			results = append(results, models.MovieSearchResult{Movie: movie, Score: candidate.score})
+		}
+	}
+	return results, nil
+}
+
+// EmbedMovie computes the embedding of a movie's current text.
+func EmbedMovie(ctx context.Context, embedder Embedder, movie models.Movie) (models.MovieEmbedding, error) {
+	text := MovieText(movie)
+	vectors, err := embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return models.MovieEmbedding{}, err
+	}
+	return models.MovieEmbedding{
+		Model:     embedder.Model(),
+		TextHash:  TextHash(text),
+		Vector:    vectors[0],
+		UpdatedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/embedding"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	embeddingPageSize  = 100
+	embeddingBatchSize = 32
+)
+
+// EmbeddingIndexer keeps every movie's embedding current. Each sweep
+// compares the hash of a movie's text and the embedding model with what
+// its stored vector was computed from, so edits, imports, genre renames
+// and a change of provider are all picked up without hooks in those paths.
+type EmbeddingIndexer struct {
+	client   *mongo.Client
+	embedder embedding.Embedder
+	interval time.Duration
+	wake     chan struct{}
+}
+
+func NewEmbeddingIndexer(client *mongo.Client, embedder embedding.Embedder, interval time.Duration) *EmbeddingIndexer {
+	return &EmbeddingIndexer{
+		client:   client,
+		embedder: embedder,
+		interval: interval,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Start sweeps straight away and then every interval until ctx is
+// cancelled.
+func (ix *EmbeddingIndexer) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(ix.interval)
+		defer ticker.Stop()
+
+		for {
+			updated, err := ix.sweep(ctx)
+			if err != nil {
+				log.Println("Error refreshing movie embeddings:", err)
+			} else if updated > 0 {
+				log.Printf("Refreshed %d movie embeddings with %s", updated, ix.embedder.Model())
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ix.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Refresh asks for a sweep without waiting for the next interval.
+func (ix *EmbeddingIndexer) Refresh() {
+	select {
+	case ix.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (ix *EmbeddingIndexer) sweep(ctx context.Context) (int, error) {
+	movies := database.OpenCollection("movies", ix.client)
+	model := ix.embedder.Model()
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(embeddingPageSize).
+		SetProjection(bson.M{"embedding.vector": 0})
+
+	updated := 0
+	var after bson.ObjectID
+	for {
+		filter := bson.M{"deleted_at": nil}
+		if !after.IsZero() {
+			filter["_id"] = bson.M{"$gt": after}
+		}
+
+		cursor, err := movies.Find(ctx, filter, opts)
+		if err != nil {
+			return updated, err
+		}
+		var page []models.Movie
+		if err := cursor.All(ctx, &page); err != nil {
+			return updated, err
+		}
+		if len(page) == 0 {
+			return updated, nil
+		}
+		after = page[len(page)-1].ID
+
+		var stale []models.Movie
+		var texts []string
+		for _, movie := range page {
+			text := embedding.MovieText(movie)
+			if movie.Embedding == nil || movie.Embedding.Model != model || movie.Embedding.TextHash != embedding.TextHash(text) {
+				stale = append(stale, movie)
+				texts = append(texts, text)
+			}
+		}
+
+		for start := 0; start < len(stale); start += embeddingBatchSize {
+			end := min(start+embeddingBatchSize, len(stale))
+			vectors, err := ix.embedder.Embed(ctx, texts[start:end])
+			if err != nil {
+				return updated, err
+			}
+
+			now := time.Now()
+			for i, movie := range stale[start:end] {
+				vector := models.MovieEmbedding{
+					Model:     model,
+					TextHash:  embedding.TextHash(texts[start+i]),
+					Vector:    vectors[i],
+					UpdatedAt: now,
+				}
+				if _, err := movies.UpdateOne(ctx, bson.M{"_id": movie.ID}, bson.M{"$set": bson.M{"embedding": vector}}); err != nil {
+					return updated, err
+				}
+				updated++
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"sync"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+)
+
+// notifier fans job updates out to the subscribers watching that job.
+type notifier struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan models.RankingJob]struct{}
+}
+
+func newNotifier() *notifier {
+	return &notifier{subscribers: map[string]map[chan models.RankingJob]struct{}{}}
+}
+
+func (n *notifier) subscribe(jobID string) (<-chan models.RankingJob, func()) {
+	ch := make(chan models.RankingJob, 8)
+
+	n.mu.Lock()
+	if n.subscribers[jobID] == nil {
+		n.subscribers[jobID] = map[chan models.RankingJob]struct{}{}
+	}
+	n.subscribers[jobID][ch] = struct{}{}
+	n.mu.Unlock()
+
+	unsubscribe := func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		delete(n.subscribers[jobID], ch)
+		if len(n.subscribers[jobID]) == 0 {
+			delete(n.subscribers, jobID)
+		}
+	}
+	return ch, unsubscribe
+}
+
+// publish never blocks; a subscriber that falls behind misses updates
+// but can always re-read the job.
+func (n *notifier) publish(job models.RankingJob) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for ch := range n.subscribers[job.ID.Hex()] {
+		select {
+		case ch <- job:
+		default:
+		}
+	}
+}
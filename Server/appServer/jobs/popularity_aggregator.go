@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/popularity"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PopularityAggregator rebuilds the trending and popular feeds from the
+// recorded movie events on a fixed interval.
+type PopularityAggregator struct {
+	client   *mongo.Client
+	interval time.Duration
+	wake     chan struct{}
+}
+
+func NewPopularityAggregator(client *mongo.Client, interval time.Duration) *PopularityAggregator {
+	return &PopularityAggregator{
+		client:   client,
+		interval: interval,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Start aggregates straight away and then every interval until ctx is
+// cancelled.
+func (a *PopularityAggregator) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := popularity.Aggregate(ctx, a.client); err != nil {
+				log.Println("Error aggregating movie feeds:", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-a.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Refresh asks for an aggregation without waiting for the next interval.
+func (a *PopularityAggregator) Refresh() {
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
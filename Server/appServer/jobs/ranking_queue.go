@@ -0,0 +1,298 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/catalog"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/ranker"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	maxJobAttempts = 5
+	baseBackoff    = 5 * time.Second
+	maxBackoff     = 5 * time.Minute
+	pollInterval   = 5 * time.Second
+	rankTimeout    = 100 * time.Second
+)
+
+var ErrJobNotFound = errors.New("ranking job not found")
+
+// RankFunc ranks a movie's admin review, giving up when ctx is done. The
+// decision it returns carries the ranking and what produced it; callers
+// fill in who and why.
+type RankFunc func(ctx context.Context, movie models.Movie, client *mongo.Client) (models.RankingDecision, error)
+
+// RankingQueue ranks admin reviews in the background. Jobs are stored in
+// the ranking_jobs collection, so queued work survives a restart, and are
+// processed by a fixed pool of workers that retry failures with
+// exponential backoff.
+type RankingQueue struct {
+	client   *mongo.Client
+	rank     RankFunc
+	workers  int
+	wake     chan struct{}
+	notifier *notifier
+}
+
+func NewRankingQueue(client *mongo.Client, rank RankFunc, workers int) *RankingQueue {
+	return &RankingQueue{
+		client:   client,
+		rank:     rank,
+		workers:  max(workers, 1),
+		wake:     make(chan struct{}, 1),
+		notifier: newNotifier(),
+	}
+}
+
+// Start requeues jobs left running by a previous process and launches the
+// workers. They stop when ctx is cancelled.
+func (q *RankingQueue) Start(ctx context.Context) {
+	_, err := q.collection().UpdateMany(ctx,
+		bson.M{"status": models.JobRunning},
+		bson.M{"$set": bson.M{"status": models.JobPending, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Println("Error requeueing running ranking jobs:", err)
+	}
+
+	for i := 0; i < q.workers; i++ {
+		go q.work(ctx)
+	}
+}
+
+// Enqueue stores a pending job for the review. Older pending jobs for the
+// same movie are superseded, since their review has been replaced.
+func (q *RankingQueue) Enqueue(ctx context.Context, imdbID, adminReview, requestedBy string) (models.RankingJob, error) {
+	now := time.Now()
+
+	superseded, err := q.collection().Find(ctx, bson.M{"imdb_id": imdbID, "status": models.JobPending})
+	if err != nil {
+		return models.RankingJob{}, err
+	}
+	var stale []models.RankingJob
+	if err := superseded.All(ctx, &stale); err != nil {
+		return models.RankingJob{}, err
+	}
+	for _, job := range stale {
+		q.setJob(ctx, bson.M{"_id": job.ID, "status": models.JobPending}, bson.M{"status": models.JobSuperseded})
+	}
+
+	job := models.RankingJob{
+		ID:            bson.NewObjectID(),
+		ImdbID:        imdbID,
+		AdminReview:   adminReview,
+		RequestedBy:   requestedBy,
+		Status:        models.JobPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if _, err := q.collection().InsertOne(ctx, job); err != nil {
+		return models.RankingJob{}, err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Get returns the current state of a job.
+func (q *RankingQueue) Get(ctx context.Context, jobID bson.ObjectID) (models.RankingJob, error) {
+	var job models.RankingJob
+	err := q.collection().FindOne(ctx, bson.M{"_id": jobID}).Decode(&job)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return job, ErrJobNotFound
+	}
+	return job, err
+}
+
+// Subscribe delivers every later state change of the job until the
+// returned function is called.
+func (q *RankingQueue) Subscribe(jobID bson.ObjectID) (<-chan models.RankingJob, func()) {
+	return q.notifier.subscribe(jobID.Hex())
+}
+
+func (q *RankingQueue) collection() *mongo.Collection {
+	return database.OpenCollection("ranking_jobs", q.client)
+}
+
+func (q *RankingQueue) work(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := q.claim(ctx)
+		if err == nil {
+			q.process(ctx, job)
+			continue
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Println("Error claiming ranking job:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim atomically moves the oldest due job to running.
+func (q *RankingQueue) claim(ctx context.Context) (models.RankingJob, error) {
+	filter := bson.M{"status": models.JobPending, "next_attempt_at": bson.M{"$lte": time.Now()}}
+	update := bson.M{
+		"$set": bson.M{"status": models.JobRunning, "updated_at": time.Now()},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job models.RankingJob
+	err := q.collection().FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err == nil {
+		q.notifier.publish(job)
+	}
+	return job, err
+}
+
+func (q *RankingQueue) process(ctx context.Context, job models.RankingJob) {
+	movies := database.OpenCollection("movies", q.client)
+
+	// the prompt may use the title and genres; the review is the job's
+	var movie models.Movie
+	err := movies.FindOne(ctx, bson.M{"imdb_id": job.ImdbID, "deleted_at": nil}, options.FindOne().SetProjection(bson.M{"embedding": 0})).Decode(&movie)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		q.setJob(ctx, bson.M{"_id": job.ID}, bson.M{"status": models.JobFailed, "last_error": "movie not found", "error_status": http.StatusNotFound})
+		return
+	}
+	if err != nil {
+		q.retryOrFail(ctx, job, err)
+		return
+	}
+	movie.AdminReview = job.AdminReview
+
+	decision, err := q.rankWithTimeout(ctx, movie)
+	if err != nil {
+		q.retryOrFail(ctx, job, err)
+		return
+	}
+
+	// a newer review may have been saved, or the ranking locked, while
+	// this one was ranked
+	result, err := movies.UpdateOne(ctx,
+		bson.M{"imdb_id": job.ImdbID, "admin_review": job.AdminReview, "ranking_locked": bson.M{"$ne": true}, "deleted_at": nil},
+		bson.M{
+			"$set":   bson.M{"ranking": decision.Ranking},
+			"$unset": bson.M{"ranking_pending": ""},
+		},
+	)
+	if err != nil {
+		q.retryOrFail(ctx, job, err)
+		return
+	}
+	if result.MatchedCount == 0 {
+		// the ranking never took effect, so the job reports none
+		q.clearPending(ctx, job)
+		q.setJob(ctx, bson.M{"_id": job.ID}, bson.M{
+			"status":     models.JobSuperseded,
+			"last_error": "ranking not applied: the review changed, the ranking was locked or the movie was deleted",
+		})
+		return
+	}
+
+	decision.ImdbID = job.ImdbID
+	decision.Source = models.DecisionReviewUpdate
+	decision.DecidedBy = job.RequestedBy
+	decision.Reference = job.ID.Hex()
+	if err := catalog.RecordRankingDecision(ctx, q.client, decision); err != nil {
+		log.Printf("Error recording ranking decision for %s: %v", job.ImdbID, err)
+	}
+
+	q.setJob(ctx, bson.M{"_id": job.ID}, bson.M{"status": models.JobSucceeded, "ranking": decision.Ranking, "last_error": ""})
+}
+
+// rankWithTimeout ranks the movie, cancelling the ranker's call once
+// rankTimeout has passed.
+func (q *RankingQueue) rankWithTimeout(ctx context.Context, movie models.Movie) (models.RankingDecision, error) {
+	rankCtx, cancel := context.WithTimeout(ctx, rankTimeout)
+	defer cancel()
+
+	decision, err := q.rank(rankCtx, movie, q.client)
+	if err != nil && errors.Is(rankCtx.Err(), context.DeadlineExceeded) {
+		return models.RankingDecision{}, errors.New("ranking timed out")
+	}
+	return decision, err
+}
+
+// retryOrFail schedules another attempt with backoff. A reply that names
+// no ranking fails the job straight away, since asking again would only
+// repeat the ranker's own retries.
+func (q *RankingQueue) retryOrFail(ctx context.Context, job models.RankingJob, cause error) {
+	log.Printf("Ranking job %s attempt %d failed: %v", job.ID.Hex(), job.Attempts, cause)
+
+	if errors.Is(cause, ranker.ErrUnparseableResponse) {
+		q.fail(ctx, job, cause, http.StatusUnprocessableEntity)
+		return
+	}
+	if job.Attempts >= maxJobAttempts {
+		q.fail(ctx, job, cause, http.StatusBadGateway)
+		return
+	}
+
+	backoff := min(baseBackoff<<(job.Attempts-1), maxBackoff)
+	q.setJob(ctx, bson.M{"_id": job.ID}, bson.M{
+		"status":          models.JobPending,
+		"last_error":      cause.Error(),
+		"next_attempt_at": time.Now().Add(backoff),
+	})
+}
+
+// fail marks the job failed with the HTTP status a synchronous ranking
+// would have answered with. The movie keeps its previous ranking.
+func (q *RankingQueue) fail(ctx context.Context, job models.RankingJob, cause error, status int) {
+	q.setJob(ctx, bson.M{"_id": job.ID}, bson.M{"status": models.JobFailed, "last_error": cause.Error(), "error_status": status})
+	q.clearPending(ctx, job)
+}
+
+// clearPending drops the movie's pending flag unless a newer review, with
+// its own job, has been saved since.
+func (q *RankingQueue) clearPending(ctx context.Context, job models.RankingJob) {
+	_, err := database.OpenCollection("movies", q.client).UpdateOne(ctx,
+		bson.M{"imdb_id": job.ImdbID, "admin_review": job.AdminReview},
+		bson.M{"$unset": bson.M{"ranking_pending": ""}},
+	)
+	if err != nil {
+		log.Printf("Error clearing pending ranking for %s: %v", job.ImdbID, err)
+	}
+}
+
+// setJob applies fields to the job matched by filter and notifies its
+// subscribers.
+func (q *RankingQueue) setJob(ctx context.Context, filter bson.M, fields bson.M) {
+	fields["updated_at"] = time.Now()
+
+	var job models.RankingJob
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := q.collection().FindOneAndUpdate(ctx, filter, bson.M{"$set": fields}, opts).Decode(&job)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Println("Error updating ranking job:", err)
+		}
+		return
+	}
+	q.notifier.publish(job)
+}
@@ -0,0 +1,340 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/catalog"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	rerankPageSize        = 50
+	defaultRerankWorkers  = 4
+	maxRerankConcurrency  = 16
+	rerankResultsPageSize = 100
+)
+
+var (
+	ErrBatchNotFound   = errors.New("re-ranking batch not found")
+	ErrBatchNotRunning = errors.New("re-ranking batch is not running")
+	ErrBatchRunning    = errors.New("re-ranking batch is already running")
+)
+
+// Reranker re-runs the review ranking over every movie with an admin
+// review. Batches advance page by page in _id order and checkpoint after
+// each page, so an interrupted or cancelled batch resumes where it stopped.
+type Reranker struct {
+	client *mongo.Client
+	rank   RankFunc
+
+	mu      sync.Mutex
+	running map[bson.ObjectID]context.CancelFunc
+}
+
+func NewReranker(client *mongo.Client, rank RankFunc) *Reranker {
+	return &Reranker{
+		client:  client,
+		rank:    rank,
+		running: map[bson.ObjectID]context.CancelFunc{},
+	}
+}
+
+// MarkInterrupted flags batches left running by a previous process so an
+// admin can resume them.
+func (r *Reranker) MarkInterrupted(ctx context.Context) error {
+	_, err := r.batches().UpdateMany(ctx,
+		bson.M{"status": models.BatchRunning},
+		bson.M{"$set": bson.M{"status": models.BatchInterrupted, "updated_at": time.Now()}},
+	)
+	return err
+}
+
+// StartBatch creates a batch and starts processing it in the background.
+func (r *Reranker) StartBatch(ctx context.Context, dryRun bool, concurrency int, requestedBy string) (models.RerankBatch, error) {
+	if concurrency < 1 {
+		concurrency = defaultRerankWorkers
+	}
+
+	total, err := r.movies().CountDocuments(ctx, reviewedMovies())
+	if err != nil {
+		return models.RerankBatch{}, err
+	}
+
+	now := time.Now()
+	batch := models.RerankBatch{
+		ID:          bson.NewObjectID(),
+		DryRun:      dryRun,
+		Concurrency: min(concurrency, maxRerankConcurrency),
+		Status:      models.BatchRunning,
+		RequestedBy: requestedBy,
+		Total:       total,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if _, err := r.batches().InsertOne(ctx, batch); err != nil {
+		return models.RerankBatch{}, err
+	}
+
+	r.launch(batch)
+	return batch, nil
+}
+
+// Resume restarts a cancelled or interrupted batch from its checkpoint.
+func (r *Reranker) Resume(ctx context.Context, batchID bson.ObjectID) (models.RerankBatch, error) {
+	r.mu.Lock()
+	_, running := r.running[batchID]
+	r.mu.Unlock()
+	if running {
+		return models.RerankBatch{}, ErrBatchRunning
+	}
+
+	var batch models.RerankBatch
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.batches().FindOneAndUpdate(ctx,
+		bson.M{"_id": batchID, "status": bson.M{"$in": bson.A{models.BatchCancelled, models.BatchInterrupted}}},
+		bson.M{"$set": bson.M{"status": models.BatchRunning, "updated_at": time.Now()}},
+		opts,
+	).Decode(&batch)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if _, getErr := r.Get(ctx, batchID); getErr != nil {
+			return batch, getErr
+		}
+		return batch, errors.New("only cancelled or interrupted batches can be resumed")
+	}
+	if err != nil {
+		return batch, err
+	}
+
+	r.launch(batch)
+	return batch, nil
+}
+
+// Cancel stops a running batch after its current page.
+func (r *Reranker) Cancel(batchID bson.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cancel, ok := r.running[batchID]
+	if !ok {
+		return ErrBatchNotRunning
+	}
+	cancel()
+	return nil
+}
+
+func (r *Reranker) Get(ctx context.Context, batchID bson.ObjectID) (models.RerankBatch, error) {
+	var batch models.RerankBatch
+	err := r.batches().FindOne(ctx, bson.M{"_id": batchID}).Decode(&batch)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return batch, ErrBatchNotFound
+	}
+	return batch, err
+}
+
+// Results lists a batch's per-movie outcomes, optionally only the movies
+// whose ranking changed, starting after the given imdb_id.
+func (r *Reranker) Results(ctx context.Context, batchID bson.ObjectID, changedOnly bool, after string) ([]models.RerankResult, error) {
+	filter := bson.M{"batch_id": batchID}
+	if changedOnly {
+		filter["changed"] = true
+	}
+	if after != "" {
+		filter["imdb_id"] = bson.M{"$gt": after}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "imdb_id", Value: 1}}).SetLimit(rerankResultsPageSize)
+	cursor, err := r.results().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []models.RerankResult{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *Reranker) launch(batch models.RerankBatch) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r.mu.Lock()
+	r.running[batch.ID] = cancel
+	r.mu.Unlock()
+
+	go func() {
+		defer func() {
+			r.mu.Lock()
+			delete(r.running, batch.ID)
+			r.mu.Unlock()
+			cancel()
+		}()
+
+		status := models.BatchCompleted
+		if err := r.run(ctx, batch); err != nil {
+			status = models.BatchInterrupted
+			if errors.Is(err, context.Canceled) {
+				status = models.BatchCancelled
+			} else {
+				log.Printf("Re-ranking batch %s stopped: %v", batch.ID.Hex(), err)
+			}
+		}
+
+		_, err := r.batches().UpdateOne(context.Background(),
+			bson.M{"_id": batch.ID},
+			bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}},
+		)
+		if err != nil {
+			log.Printf("Error finishing re-ranking batch %s: %v", batch.ID.Hex(), err)
+		}
+	}()
+}
+
+func (r *Reranker) run(ctx context.Context, batch models.RerankBatch) error {
+	checkpoint := batch.Checkpoint
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		filter := reviewedMovies()
+		if !checkpoint.IsZero() {
+			filter["_id"] = bson.M{"$gt": checkpoint}
+		}
+		opts := options.Find().
+			SetSort(bson.D{{Key: "_id", Value: 1}}).
+			SetLimit(rerankPageSize).
+			SetProjection(bson.M{"embedding": 0})
+
+		cursor, err := r.movies().Find(ctx, filter, opts)
+		if err != nil {
+			return err
+		}
+		var page []models.Movie
+		if err := cursor.All(ctx, &page); err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			return nil
+		}
+
+		var changed, failed atomic.Int64
+		sem := make(chan struct{}, batch.Concurrency)
+		var wg sync.WaitGroup
+		for _, movie := range page {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(movie models.Movie) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				result := r.rerankMovie(ctx, batch, movie)
+				if result.Error != "" {
+					failed.Add(1)
+				} else if result.Changed {
+					changed.Add(1)
+				}
+			}(movie)
+		}
+		wg.Wait()
+
+		checkpoint = page[len(page)-1].ID
+		_, err = r.batches().UpdateOne(context.Background(),
+			bson.M{"_id": batch.ID},
+			bson.M{
+				"$set": bson.M{"checkpoint": checkpoint, "updated_at": time.Now()},
+				"$inc": bson.M{"processed": len(page), "changed": changed.Load(), "failed": failed.Load()},
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// rerankMovie ranks one movie and records the outcome. Outside dry runs a
+// changed ranking is written back unless the review changed meanwhile.
+func (r *Reranker) rerankMovie(ctx context.Context, batch models.RerankBatch, movie models.Movie) models.RerankResult {
+	result := models.RerankResult{
+		BatchID:    batch.ID,
+		ImdbID:     movie.ImdbID,
+		Title:      movie.Title,
+		OldRanking: movie.Ranking,
+	}
+
+	decision, err := r.rank(ctx, movie, r.client)
+	if err == nil {
+		ranking := decision.Ranking
+		result.NewRanking = &ranking
+		result.Changed = movie.Ranking.RankingValue != ranking.RankingValue || movie.Ranking.RankingName != ranking.RankingName || movie.RankingPending
+
+		if result.Changed && !batch.DryRun {
+			err = r.applyDecision(batch, movie, decision)
+		}
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	// keyed on batch and movie so a resumed page overwrites its first attempt
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+	_, saveErr := r.results().ReplaceOne(ctx,
+		bson.M{"batch_id": batch.ID, "imdb_id": movie.ImdbID},
+		result,
+		options.Replace().SetUpsert(true),
+	)
+	if saveErr != nil {
+		log.Printf("Error saving re-ranking result for %s: %v", movie.ImdbID, saveErr)
+	}
+	return result
+}
+
+func (r *Reranker) applyDecision(batch models.RerankBatch, movie models.Movie, decision models.RankingDecision) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	result, err := r.movies().UpdateOne(ctx,
+		bson.M{"_id": movie.ID, "admin_review": movie.AdminReview, "ranking_locked": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"ranking": decision.Ranking}},
+	)
+	if err != nil || result.MatchedCount == 0 {
+		return err
+	}
+
+	decision.ImdbID = movie.ImdbID
+	decision.Source = models.DecisionRerank
+	decision.DecidedBy = batch.RequestedBy
+	decision.Reference = batch.ID.Hex()
+	return catalog.RecordRankingDecision(ctx, r.client, decision)
+}
+
+// reviewedMovies matches the movies a re-rank covers: reviewed, not
+// deleted and without a locked ranking.
+func reviewedMovies() bson.M {
+	return bson.M{"admin_review": bson.M{"$nin": bson.A{"", nil}}, "ranking_locked": bson.M{"$ne": true}, "deleted_at": nil}
+}
+
+func (r *Reranker) batches() *mongo.Collection {
+	return database.OpenCollection("rerank_batches", r.client)
+}
+
+func (r *Reranker) results() *mongo.Collection {
+	return database.OpenCollection("rerank_results", r.client)
+}
+
+func (r *Reranker) movies() *mongo.Collection {
+	return database.OpenCollection("movies", r.client)
+}
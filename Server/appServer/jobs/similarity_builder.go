@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/recommend"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// SimilarityBuilder recomputes the recommender's item-item similarities
+// from ratings, watchlists and watch history on a fixed interval.
+type SimilarityBuilder struct {
+	client   *mongo.Client
+	interval time.Duration
+	wake     chan struct{}
+}
+
+func NewSimilarityBuilder(client *mongo.Client, interval time.Duration) *SimilarityBuilder {
+	return &SimilarityBuilder{
+		client:   client,
+		interval: interval,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Start builds straight away and then every interval until ctx is
+// cancelled.
+func (b *SimilarityBuilder) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(b.interval)
+		defer ticker.Stop()
+
+		for {
+			started := time.Now()
+			movies, err := recommend.BuildSimilarities(ctx, b.client)
+			if err != nil {
+				log.Println("Error building movie similarities:", err)
+			} else {
+				log.Printf("Built similarities for %d movies in %s", movies, time.Since(started).Round(time.Millisecond))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Refresh asks for a build without waiting for the next interval.
+func (b *SimilarityBuilder) Refresh() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
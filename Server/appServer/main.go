@@ -1,10 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strconv"
 	"time"
 
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/catalog"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/controllers"
 	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/embedding"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/jobs"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/recommend"
 	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/routes"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -13,6 +21,14 @@ import (
 
 func main() {
 
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	router := gin.Default()
 
 	router.GET("/hello", func(c *gin.Context) {
@@ -21,19 +37,63 @@ func main() {
 
 	var client *mongo.Client = database.Connect()
 
+	if err := database.EnsureIndexes(client); err != nil {
+		fmt.Println("Some indexes could not be created:", err)
+	}
+
+	if err := catalog.MigrateUnrankedSentinel(context.Background(), client); err != nil {
+		fmt.Println("Failed to migrate unranked ranking level:", err)
+	}
+
+	rankingWorkers := 2
+	if workers, err := strconv.Atoi(os.Getenv("RANKING_WORKERS")); err == nil && workers > 0 {
+		rankingWorkers = workers
+	}
+	rankingQueue := jobs.NewRankingQueue(client, controllers.GetReviewRankingDecision, rankingWorkers)
+	rankingQueue.Start(context.Background())
+
+	reranker := jobs.NewReranker(client, controllers.GetReviewRankingDecision)
+	if err := reranker.MarkInterrupted(context.Background()); err != nil {
+		fmt.Println("Failed to mark interrupted re-ranking batches:", err)
+	}
+
+	embedder := embedding.FromEnv()
+	embeddingInterval := 10 * time.Minute
+	if interval, err := time.ParseDuration(os.Getenv("EMBEDDING_INTERVAL")); err == nil && interval > 0 {
+		embeddingInterval = interval
+	}
+	embeddingIndexer := jobs.NewEmbeddingIndexer(client, embedder, embeddingInterval)
+	embeddingIndexer.Start(context.Background())
+
+	similarityInterval := time.Hour
+	if interval, err := time.ParseDuration(os.Getenv("RECOMMENDER_INTERVAL")); err == nil && interval > 0 {
+		similarityInterval = interval
+	}
+	similarityBuilder := jobs.NewSimilarityBuilder(client, similarityInterval)
+	similarityBuilder.Start(context.Background())
+
+	recommender := recommend.ServiceFromEnv(client)
+
+	popularityInterval := 15 * time.Minute
+	if interval, err := time.ParseDuration(os.Getenv("POPULARITY_INTERVAL")); err == nil && interval > 0 {
+		popularityInterval = interval
+	}
+	popularityAggregator := jobs.NewPopularityAggregator(client, popularityInterval)
+	popularityAggregator.Start(context.Background())
+
 	config := cors.Config{}
 
 	config.AllowAllOrigins = true
-	config.AllowMethods = []string{"GET", "POST", "PATCH"}
+	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
 	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization"}
-	config.ExposeHeaders = []string{"Content-Length"}
+	config.ExposeHeaders = []string{"Content-Length", "X-Recommendation-Strategy", "X-Recommendation-Experiment", "X-Recommendation-Exposure"}
 	config.MaxAge = 12 * time.Hour
 
 	router.Use(cors.New(config))
 	router.Use(gin.Logger())
 
 	routes.SetupUnProtectedRoutes(router, client)
-	routes.SetupProtectedRoutes(router, client)
+	routes.SetupProtectedRoutes(router, client, rankingQueue, reranker, embedder, embeddingIndexer, similarityBuilder, recommender, popularityAggregator)
 
 	if err := router.Run(":8080"); err != nil {
 		fmt.Println("Failed to start server:", err)
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/utils"
 	"github.com/gin-gonic/gin"
@@ -33,3 +34,24 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalAuthMiddleware identifies the caller on public routes when they
+// send a valid token, so responses can be personalised. Requests without
+// one, or with a bad one, carry on anonymously.
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Request.Header.Get("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			c.Next()
+			return
+		}
+
+		claims, err := utils.ValidateToken(strings.TrimPrefix(header, "Bearer "))
+		if err == nil {
+			c.Set("userId", claims.UserID)
+			c.Set("role", claims.Role)
+		}
+
+		c.Next()
+	}
+}
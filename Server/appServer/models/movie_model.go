@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
@@ -12,6 +14,8 @@ type Genre struct {
 type Ranking struct {
 	RankingValue int    `bson:"ranking_value" json:"ranking_value" validate:"required"`
 	RankingName  string `bson:"ranking_name" json:"ranking_name" validate:"required"`
+	Unranked     bool   `bson:"unranked,omitempty" json:"unranked,omitempty"`
+	Retired      bool   `bson:"retired,omitempty" json:"retired,omitempty"`
 }
 
 type Movie struct {
@@ -23,4 +27,94 @@ type Movie struct {
 	Genre       []Genre       `bson:"genre" json:"genre" validate:"required,dive"`
 	AdminReview string        `bson:"admin_review" json:"admin_review"`
 	Ranking     Ranking       `bson:"ranking" json:"ranking" validate:"required"`
+	DeletedAt   *time.Time    `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	// a locked ranking was set by an admin and is skipped by re-ranks
+	RankingLocked bool `bson:"ranking_locked,omitempty" json:"ranking_locked,omitempty"`
+	// set while a ranking job for the current review is queued or running;
+	// Ranking keeps the previous ranking until the job succeeds
+	RankingPending bool `bson:"ranking_pending,omitempty" json:"ranking_pending,omitempty"`
+	// approved copy only; drafts stay in Copy until an admin approves them
+	Synopsis string     `bson:"synopsis,omitempty" json:"synopsis,omitempty"`
+	Tagline  string     `bson:"tagline,omitempty" json:"tagline,omitempty"`
+	Copy     *MovieCopy `bson:"copy,omitempty" json:"-"`
+	// aggregated from user reviews
+	AverageRating float64 `bson:"average_rating,omitempty" json:"average_rating,omitempty"`
+	RatingCount   int64   `bson:"rating_count,omitempty" json:"rating_count,omitempty"`
+	// whether the movie is on the caller's watchlist; never stored, and
+	// left out for anonymous callers
+	OnWatchlist *bool `bson:"-" json:"on_watchlist,omitempty"`
+	// vector for similarity search; too large to send to clients
+	Embedding *MovieEmbedding `bson:"embedding,omitempty" json:"-"`
+}
+
+// states of a movie's generated copy
+const (
+	CopyDraft    = "draft"
+	CopyApproved = "approved"
+)
+
+// Latest generated synopsis and tagline of a movie and its review state
+type MovieCopy struct {
+	Synopsis   string     `bson:"synopsis" json:"synopsis"`
+	Tagline    string     `bson:"tagline" json:"tagline"`
+	Status     string     `bson:"status" json:"status"`
+	Model      string     `bson:"model" json:"model"`
+	Prompt     string     `bson:"prompt" json:"prompt"`
+	DraftedBy  string     `bson:"drafted_by" json:"drafted_by"`
+	DraftedAt  time.Time  `bson:"drafted_at" json:"drafted_at"`
+	ApprovedBy string     `bson:"approved_by,omitempty" json:"approved_by,omitempty"`
+	ApprovedAt *time.Time `bson:"approved_at,omitempty" json:"approved_at,omitempty"`
+}
+
+// Embedding of a movie's title, genres and admin review
+type MovieEmbedding struct {
+	Model     string    `bson:"model" json:"model"`
+	TextHash  string    `bson:"text_hash" json:"text_hash"`
+	Vector    []float32 `bson:"vector" json:"vector"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Envelope returned by the paginated movie listing
+type MovieListResponse struct {
+	Movies     []Movie `json:"movies"`
+	Total      int64   `json:"total"`
+	Page       int64   `json:"page,omitempty"`
+	Limit      int64   `json:"limit"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// how a search result matched; scores are only comparable within one kind
+const (
+	SearchMatchText  = "text"
+	SearchMatchFuzzy = "fuzzy"
+)
+
+// Single hit returned by the movie search endpoint
+type MovieSearchResult struct {
+	Movie      Movie             `json:"movie"`
+	Score      float64           `json:"score"`
+	Match      string            `json:"match,omitempty"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+// Outcome of a single row of a bulk movie import
+type ImportRowResult struct {
+	Row    int    `json:"row"`
+	ImdbID string `json:"imdb_id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Per-row report returned by a bulk movie import
+type ImportReport struct {
+	Created  int               `json:"created"`
+	Updated  int               `json:"updated"`
+	Rejected int               `json:"rejected"`
+	Rows     []ImportRowResult `json:"rows"`
+}
+
+// Movies whose titles look like the same film
+type DuplicateTitleGroup struct {
+	Similarity float64 `json:"similarity"`
+	Movies     []Movie `json:"movies"`
 }
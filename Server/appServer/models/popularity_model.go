@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// kinds of engagement that feed the trending and popular lists
+const (
+	EventView         = "view"
+	EventWatchlistAdd = "watchlist_add"
+	EventRating       = "rating"
+)
+
+// One user's engagement with a movie. The id combines the kind, movie,
+// user and day, so a user counts at most once per kind, movie and day.
+type MovieEvent struct {
+	ID         string    `bson:"_id" json:"_id"`
+	Type       string    `bson:"type" json:"type"`
+	ImdbID     string    `bson:"imdb_id" json:"imdb_id"`
+	UserID     string    `bson:"user_id" json:"user_id"`
+	Weight     float64   `bson:"weight" json:"weight"`
+	OccurredAt time.Time `bson:"occurred_at" json:"occurred_at"`
+}
+
+// the two feeds built from movie events
+const (
+	FeedTrending = "trending"
+	FeedPopular  = "popular"
+)
+
+// A ranked feed for one window, over the whole catalog or one genre
+type MovieFeed struct {
+	ID         string      `bson:"_id" json:"-"`
+	Kind       string      `bson:"kind" json:"kind"`
+	Window     string      `bson:"window" json:"window"`
+	Genre      string      `bson:"genre,omitempty" json:"genre,omitempty"`
+	Entries    []FeedEntry `bson:"entries" json:"-"`
+	ComputedAt time.Time   `bson:"computed_at" json:"computed_at"`
+	Movies     []Movie     `bson:"-" json:"movies"`
+}
+
+type FeedEntry struct {
+	ImdbID string  `bson:"imdb_id" json:"imdb_id"`
+	Score  float64 `bson:"score" json:"score"`
+	Events int64   `bson:"events" json:"events"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// One immutable version of a named prompt template. The highest version
+// of a name is the one in use.
+type PromptTemplate struct {
+	ID      bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	Name    string        `bson:"name" json:"name"`
+	Version int           `bson:"version" json:"version"`
+	Body    string        `bson:"body" json:"body" validate:"required,max=10000"`
+	Note    string        `bson:"note,omitempty" json:"note,omitempty" validate:"max=500"`
+	// version whose body this one restored, when created by a rollback
+	RolledBackFrom int       `bson:"rolled_back_from,omitempty" json:"rolled_back_from,omitempty"`
+	CreatedBy      string    `bson:"created_by" json:"created_by"`
+	CreatedAt      time.Time `bson:"created_at" json:"created_at"`
+}
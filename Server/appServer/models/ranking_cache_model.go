@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Cached ranker answer, keyed on a hash of what produced it
+type RankingCacheEntry struct {
+	Key           string    `bson:"_id" json:"key"`
+	PromptVersion int       `bson:"prompt_version" json:"prompt_version"`
+	Model         string    `bson:"model" json:"model"`
+	Response      string    `bson:"response" json:"response"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+	ExpiresAt     time.Time `bson:"expires_at" json:"expires_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// what produced a ranking decision
+const (
+	DecisionReviewUpdate = "review_update"
+	DecisionRerank       = "rerank"
+	DecisionOverride     = "override"
+	DecisionLock         = "lock"
+	DecisionImport       = "import"
+	// the movie's level was renamed, moved or retired
+	DecisionScaleChange = "scale_change"
+)
+
+// Audit record of a ranking given to a movie
+type RankingDecision struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ImdbID      string        `bson:"imdb_id" json:"imdb_id"`
+	Ranking     Ranking       `bson:"ranking" json:"ranking"`
+	Source      string        `bson:"source" json:"source"`
+	AdminReview string        `bson:"admin_review" json:"admin_review"`
+	Prompt      string        `bson:"prompt,omitempty" json:"prompt,omitempty"`
+	// version of the prompt template used; 0 is BASE_PROMPT_TEMPLATE
+	PromptVersion int    `bson:"prompt_version,omitempty" json:"prompt_version,omitempty"`
+	Model         string `bson:"model,omitempty" json:"model,omitempty"`
+	RawResponse   string `bson:"raw_response,omitempty" json:"raw_response,omitempty"`
+	Cached        bool   `bson:"cached,omitempty" json:"cached,omitempty"`
+	Locked        bool   `bson:"locked,omitempty" json:"locked,omitempty"`
+	Reason        string `bson:"reason,omitempty" json:"reason,omitempty"`
+	DecidedBy     string `bson:"decided_by" json:"decided_by"`
+	// ranking job or re-ranking batch that made the decision
+	Reference string    `bson:"reference,omitempty" json:"reference,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
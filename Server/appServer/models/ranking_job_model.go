@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// states of a review ranking job
+const (
+	JobPending    = "pending"
+	JobRunning    = "running"
+	JobSucceeded  = "succeeded"
+	JobFailed     = "failed"
+	JobSuperseded = "superseded"
+)
+
+// Background request to rank an admin review
+type RankingJob struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"job_id"`
+	ImdbID      string        `bson:"imdb_id" json:"imdb_id"`
+	AdminReview string        `bson:"admin_review" json:"admin_review"`
+	RequestedBy string        `bson:"requested_by" json:"requested_by"`
+	Status      string        `bson:"status" json:"status"`
+	Attempts    int           `bson:"attempts" json:"attempts"`
+	LastError   string        `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	// for a failed job, the HTTP status ranking synchronously would have
+	// returned: 422 when the ranker's reply named no ranking, 502 when the
+	// ranker kept failing
+	ErrorStatus   int       `bson:"error_status,omitempty" json:"error_status,omitempty"`
+	Ranking       *Ranking  `bson:"ranking,omitempty" json:"ranking,omitempty"`
+	NextAttemptAt time.Time `bson:"next_attempt_at" json:"next_attempt_at"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Finished reports whether the job will not change any more.
+func (j RankingJob) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobSuperseded
+}
+
+// states of a catalog re-ranking batch
+const (
+	BatchRunning     = "running"
+	BatchCompleted   = "completed"
+	BatchCancelled   = "cancelled"
+	BatchInterrupted = "interrupted"
+)
+
+// Re-ranking of every reviewed movie in the catalog
+type RerankBatch struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"batch_id"`
+	DryRun      bool          `bson:"dry_run" json:"dry_run"`
+	Concurrency int           `bson:"concurrency" json:"concurrency"`
+	Status      string        `bson:"status" json:"status"`
+	RequestedBy string        `bson:"requested_by" json:"requested_by"`
+	Total       int64         `bson:"total" json:"total"`
+	Processed   int64         `bson:"processed" json:"processed"`
+	Changed     int64         `bson:"changed" json:"changed"`
+	Failed      int64         `bson:"failed" json:"failed"`
+	// every movie up to and including this _id has been processed
+	Checkpoint bson.ObjectID `bson:"checkpoint,omitempty" json:"-"`
+	CreatedAt  time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// Old and new ranking of one movie in a re-ranking batch
+type RerankResult struct {
+	BatchID    bson.ObjectID `bson:"batch_id" json:"batch_id"`
+	ImdbID     string        `bson:"imdb_id" json:"imdb_id"`
+	Title      string        `bson:"title" json:"title"`
+	OldRanking Ranking       `bson:"old_ranking" json:"old_ranking"`
+	NewRanking *Ranking      `bson:"new_ranking,omitempty" json:"new_ranking,omitempty"`
+	Changed    bool          `bson:"changed" json:"changed"`
+	Error      string        `bson:"error,omitempty" json:"error,omitempty"`
+}
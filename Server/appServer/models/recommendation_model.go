@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// The movies most often liked by the same users as one movie, computed
+// by the recommender's batch job
+type MovieSimilarity struct {
+	ImdbID     string         `bson:"_id" json:"imdb_id"`
+	Neighbours []SimilarMovie `bson:"neighbours" json:"neighbours"`
+	ComputedAt time.Time      `bson:"computed_at" json:"computed_at"`
+}
+
+type SimilarMovie struct {
+	ImdbID string  `bson:"imdb_id" json:"imdb_id"`
+	Score  float64 `bson:"score" json:"score"`
+	// how many users interacted with both movies
+	Support int `bson:"support" json:"support"`
+}
+
+// A list of recommendations served to a user, logged so strategies can be
+// compared offline against what the user went on to watch
+type RecommendationExposure struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID     string        `bson:"user_id" json:"user_id"`
+	Experiment string        `bson:"experiment,omitempty" json:"experiment,omitempty"`
+	Strategy   string        `bson:"strategy" json:"strategy"`
+	ImdbIDs    []string      `bson:"imdb_ids" json:"imdb_ids"`
+	ServedAt   time.Time     `bson:"served_at" json:"served_at"`
+}
+
+// why a movie was recommended
+const (
+	ReasonFavouriteGenre   = "favourite_genre"
+	ReasonSimilarToWatched = "similar_to_watched"
+	ReasonHighlyRanked     = "highly_ranked"
+	ReasonTrending         = "trending"
+)
+
+type RecommendationReason struct {
+	Type string `json:"type"`
+	// the favourite genre matched
+	Genre string `json:"genre,omitempty"`
+	// the title the user watched, rated or listed that this one is similar to
+	ImdbID string `json:"imdb_id,omitempty"`
+	Title  string `json:"title,omitempty"`
+	// the movie's ranking level
+	Ranking string `json:"ranking,omitempty"`
+	// the trending feed window the movie is in
+	Window string `json:"window,omitempty"`
+}
+
+type RecommendedMovie struct {
+	Movie       Movie                  `json:"movie"`
+	Reasons     []RecommendationReason `json:"reasons"`
+	Explanation string                 `json:"explanation"`
+}
+
+// Version 2 of the /recommendedmovies response; version 1 is a plain list
+// of movies
+type RecommendationResponse struct {
+	Version         int                `json:"version"`
+	Strategy        string             `json:"strategy"`
+	Experiment      string             `json:"experiment,omitempty"`
+	ExposureID      string             `json:"exposure_id,omitempty"`
+	Recommendations []RecommendedMovie `json:"recommendations"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// A user's star rating of a movie, with an optional written review
+type UserReview struct {
+	ID       bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ImdbID   string        `bson:"imdb_id" json:"imdb_id"`
+	UserID   string        `bson:"user_id" json:"user_id,omitempty"`
+	UserName string        `bson:"user_name" json:"user_name"`
+	Rating   int           `bson:"rating" json:"rating" validate:"required,min=1,max=5"`
+	Body     string        `bson:"body,omitempty" json:"body,omitempty" validate:"max=5000"`
+	// left out of public listings
+	Moderation *ReviewModeration `bson:"moderation,omitempty" json:"moderation,omitempty"`
+	Reports    []ReviewReport    `bson:"reports,omitempty" json:"reports,omitempty"`
+	// when the review was first submitted; edits only move UpdatedAt
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// moderation states of a user review; hidden and rejected reviews are not
+// shown or counted in a movie's rating
+const (
+	ReviewPublished = "published"
+	ReviewFlagged   = "flagged"
+	ReviewHidden    = "hidden"
+	ReviewApproved  = "approved"
+	ReviewRejected  = "rejected"
+)
+
+type ReviewModeration struct {
+	Status     string     `bson:"status" json:"status"`
+	Score      float64    `bson:"score" json:"score"`
+	Reasons    []string   `bson:"reasons,omitempty" json:"reasons,omitempty"`
+	Model      string     `bson:"model,omitempty" json:"model,omitempty"`
+	ReviewedBy string     `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+	Note       string     `bson:"note,omitempty" json:"note,omitempty"`
+}
+
+// A user's report that a review is abusive or spam
+type ReviewReport struct {
+	UserID     string    `bson:"user_id" json:"user_id"`
+	Reason     string    `bson:"reason,omitempty" json:"reason,omitempty" validate:"max=500"`
+	ReportedAt time.Time `bson:"reported_at" json:"reported_at"`
+}
+
+type ReviewListResponse struct {
+	Reviews []UserReview `json:"reviews"`
+	Total   int64        `json:"total"`
+	Page    int64        `json:"page"`
+	Limit   int64        `json:"limit"`
+}
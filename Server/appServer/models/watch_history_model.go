@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Where a user is in a movie; one entry per user and movie
+type WatchHistoryEntry struct {
+	ID       bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID   string        `bson:"user_id" json:"user_id"`
+	ImdbID   string        `bson:"imdb_id" json:"imdb_id"`
+	Position float64       `bson:"position_seconds" json:"position_seconds"`
+	Duration float64       `bson:"duration_seconds" json:"duration_seconds"`
+	// share of the movie played at Position, between 0 and 1
+	Progress float64 `bson:"progress" json:"progress"`
+	// set while the latest session is past the completion threshold; a
+	// rewatch clears it until that session completes too
+	Completed      bool       `bson:"completed" json:"completed"`
+	CompletedAt    *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	TimesCompleted int64      `bson:"times_completed" json:"times_completed"`
+	SessionCount   int64      `bson:"session_count" json:"session_count"`
+	LastSessionID  string     `bson:"last_session_id" json:"last_session_id"`
+	FirstWatched   time.Time  `bson:"first_watched_at" json:"first_watched_at"`
+	LastWatched    time.Time  `bson:"last_watched_at" json:"last_watched_at"`
+	Movie          *Movie     `bson:"-" json:"movie,omitempty"`
+}
+
+// One sitting with the player, from its first progress report to its last
+type WatchSession struct {
+	ID            bson.ObjectID `bson:"_id,omitempty" json:"session_id"`
+	UserID        string        `bson:"user_id" json:"user_id"`
+	ImdbID        string        `bson:"imdb_id" json:"imdb_id"`
+	StartPosition float64       `bson:"start_position_seconds" json:"start_position_seconds"`
+	Position      float64       `bson:"position_seconds" json:"position_seconds"`
+	Duration      float64       `bson:"duration_seconds" json:"duration_seconds"`
+	Completed     bool          `bson:"completed" json:"completed"`
+	StartedAt     time.Time     `bson:"started_at" json:"started_at"`
+	UpdatedAt     time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// A progress report from the player
+type PlaybackProgress struct {
+	// empty on the first report of a session; later reports send back
+	// the session_id the first one returned
+	SessionID string  `json:"session_id"`
+	Position  float64 `json:"position_seconds" validate:"min=0"`
+	Duration  float64 `json:"duration_seconds" validate:"required,gt=0"`
+}
+
+type WatchHistoryResponse struct {
+	Entries []WatchHistoryEntry `json:"entries"`
+	Total   int64               `json:"total"`
+	Page    int64               `json:"page"`
+	Limit   int64               `json:"limit"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// A user's watchlist; the order of Items is the order the user chose
+type Watchlist struct {
+	ID        bson.ObjectID   `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID    string          `bson:"user_id" json:"user_id"`
+	Items     []WatchlistItem `bson:"items" json:"items"`
+	UpdatedAt time.Time       `bson:"updated_at" json:"updated_at"`
+}
+
+type WatchlistItem struct {
+	ImdbID  string    `bson:"imdb_id" json:"imdb_id"`
+	AddedAt time.Time `bson:"added_at" json:"added_at"`
+}
@@ -0,0 +1,252 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/ranker"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/search"
+)
+
+const (
+	defaultHideThreshold   = 0.7
+	defaultReviewThreshold = 0.3
+	defaultReportThreshold = 3
+	classifierTimeout      = 15 * time.Second
+)
+
+// words that count as profanity on their own
+var profanity = map[string]bool{
+	"asshole": true, "bastard": true, "bollocks": true, "cock": true, "dick": true,
+	"fag": true, "nigger": true, "prick": true, "pussy": true, "retard": true,
+	"slut": true, "twat": true, "whore": true,
+}
+
+// stems that are profane in any form, such as "fucking" or "bitches"
+var profaneStems = []string{"bitch", "bullshit", "cunt", "fuck", "motherfuck", "shit", "wank"}
+
+// undo common letter substitutions before matching profanity
+var leet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s", "!", "i")
+
+var (
+	link    = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9-]+\.(?:com|net|org|info|biz|xyz|ru|io|ly|co)\b(?:/\S*)?`)
+	contact = regexp.MustCompile(`(?i)[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}|\+?\d(?:[\s.-]?\d){8,}`)
+)
+
+// Config holds the moderation thresholds and the optional LLM classifier.
+type Config struct {
+	// reviews scoring at least this are hidden until an admin approves them
+	HideThreshold float64
+	// reviews scoring at least this stay visible but are queued for an admin
+	ReviewThreshold float64
+	// reports from this many users queue a review
+	ReportThreshold int
+	Classifier      ranker.Generator
+}
+
+// FromEnv reads MODERATION_HIDE_THRESHOLD, MODERATION_REVIEW_THRESHOLD and
+// MODERATION_REPORT_THRESHOLD. MODERATION_LLM=true adds the LLM configured
+// for ranking as a classifier; without it scoring is purely local.
+func FromEnv() Config {
+	config := Config{
+		HideThreshold:   envFloat("MODERATION_HIDE_THRESHOLD", defaultHideThreshold),
+		ReviewThreshold: envFloat("MODERATION_REVIEW_THRESHOLD", defaultReviewThreshold),
+		ReportThreshold: defaultReportThreshold,
+	}
+	if reports, err := strconv.Atoi(os.Getenv("MODERATION_REPORT_THRESHOLD")); err == nil && reports > 0 {
+		config.ReportThreshold = reports
+	}
+
+	if os.Getenv("MODERATION_LLM") == "true" {
+		classifier, err := ranker.GeneratorFromEnv()
+		if err != nil {
+			log.Println("Moderation LLM classifier disabled:", err)
+		} else {
+			config.Classifier = classifier
+		}
+	}
+	return config
+}
+
+func envFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || value <= 0 || value > 1 {
+		return fallback
+	}
+	return value
+}
+
+// Result is a review's abuse and spam score, from 0 (clean) to 1, and
+// what contributed to it.
+type Result struct {
+	Score   float64
+	Reasons []string
+	Model   string
+}
+
+// Status is where a review with this result starts out.
+func (config Config) Status(result Result) string {
+	switch {
+	case result.Score >= config.HideThreshold:
+		return models.ReviewHidden
+	case result.Score >= config.ReviewThreshold:
+		return models.ReviewFlagged
+	}
+	return models.ReviewPublished
+}
+
+// Score rates a review's text with the local heuristics and, when one is
+// configured, the LLM classifier; the higher of the two wins. A failing
+// classifier is logged and skipped so reviews can always be posted.
+func (config Config) Score(ctx context.Context, text string) Result {
+	result := heuristicScore(text)
+	if config.Classifier == nil || strings.TrimSpace(text) == "" {
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, classifierTimeout)
+	defer cancel()
+
+	classified, err := classify(ctx, config.Classifier, text)
+	if err != nil {
+		log.Println("Moderation classifier failed:", err)
+		return result
+	}
+	if classified.Score > result.Score {
+		result.Score = classified.Score
+	}
+	result.Reasons = append(result.Reasons, classified.Reasons...)
+	result.Model = classified.Model
+	return result
+}
+
+func heuristicScore(text string) Result {
+	var result Result
+	add := func(score float64, reason string) {
+		result.Score += score
+		result.Reasons = append(result.Reasons, reason)
+	}
+
+	words := search.Tokenize(leet.Replace(strings.ToLower(text)))
+	profane := 0
+	for _, word := range words {
+		if profanity[word] {
+			profane++
+			continue
+		}
+		for _, stem := range profaneStems {
+			if strings.HasPrefix(word, stem) {
+				profane++
+				break
+			}
+		}
+	}
+	if profane > 0 {
+		add(min(0.35*float64(profane), 0.8), fmt.Sprintf("profanity (%d)", profane))
+	}
+
+	if links := len(link.FindAllString(text, -1)); links > 0 {
+		add(min(0.35*float64(links), 0.8), fmt.Sprintf("links (%d)", links))
+	}
+	if contact.MatchString(text) {
+		add(0.3, "contact details")
+	}
+	if repeatedRun(text, 6) {
+		add(0.15, "repeated characters")
+	}
+	if shouting(text) {
+		add(0.15, "mostly capitals")
+	}
+	if len(words) >= 10 && distinctRatio(words) < 0.3 {
+		add(0.3, "repeated words")
+	}
+
+	result.Score = math.Min(result.Score, 1)
+	return result
+}
+
+// repeatedRun reports whether text has a character n or more times in a
+// row, as in "soooooo" or "!!!!!!".
+func repeatedRun(text string, n int) bool {
+	run := 0
+	var previous rune
+	for _, r := range text {
+		if r == previous {
+			run++
+		} else {
+			previous, run = r, 1
+		}
+		if run >= n && !unicode.IsSpace(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func shouting(text string) bool {
+	upper, letters := 0, 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters >= 20 && float64(upper)/float64(letters) > 0.7
+}
+
+func distinctRatio(words []string) float64 {
+	distinct := map[string]bool{}
+	for _, word := range words {
+		distinct[word] = true
+	}
+	return float64(len(distinct)) / float64(len(words))
+}
+
+const classifierPrompt = `You moderate user reviews on a movie site. Rate how likely the review below is abusive, hateful, harassing, sexually explicit or spam, from 0 (fine) to 1 (certainly). Harsh criticism of a movie is fine.
+
+Review:
+%s
+
+Respond with only a JSON object of the form {"score": <number>, "reason": "<a few words>"}.`
+
+var jsonObject = regexp.MustCompile(`(?s)\{.*?\}`)
+
+func classify(ctx context.Context, classifier ranker.Generator, text string) (Result, error) {
+	response, err := classifier.Generate(ctx, fmt.Sprintf(classifierPrompt, text))
+	if err != nil {
+		return Result{}, err
+	}
+
+	var answer struct {
+		Score  float64 `json:"score"`
+		Reason string  `json:"reason"`
+	}
+	match := jsonObject.FindString(response.Response)
+	if match == "" {
+		return Result{}, fmt.Errorf("no JSON object in classifier reply %q", response.Response)
+	}
+	if err := json.Unmarshal([]byte(match), &answer); err != nil {
+		return Result{}, err
+	}
+	if answer.Score < 0 || answer.Score > 1 {
+		return Result{}, fmt.Errorf("classifier score %v out of range", answer.Score)
+	}
+
+	result := Result{Score: answer.Score, Model: response.Model}
+	if answer.Reason != "" && answer.Score >= defaultReviewThreshold {
+		result.Reasons = []string{"classifier: " + answer.Reason}
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package moderation
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+)
+
+func TestHeuristicScore(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		wantReasons []string
+	}{
+		{name: "clean", text: "A slow start, but the last hour is terrific."},
+		{name: "harsh but clean", text: "Shallow, dull and a complete waste of two hours."},
+		{name: "profanity", text: "What a shit ending", wantReasons: []string{"profanity (1)"}},
+		{name: "profane stem", text: "fucking brilliant, bitches", wantReasons: []string{"profanity (2)"}},
+		{name: "leetspeak", text: "total sh1t", wantReasons: []string{"profanity (1)"}},
+		{name: "no false positive inside words", text: "Scunthorpe and Essex cockpit scenes", wantReasons: nil},
+		{name: "link", text: "watch free at www.example.com now", wantReasons: []string{"links (1)"}},
+		{name: "bare domain", text: "full movie on cheapflix.xyz", wantReasons: []string{"links (1)"}},
+		{name: "email", text: "write to me at fan@example.org", wantReasons: []string{"links (1)", "contact details"}},
+		{name: "phone number", text: "call +1 555 123 4567 for tickets", wantReasons: []string{"contact details"}},
+		{name: "repeated characters", text: "soooooo good", wantReasons: []string{"repeated characters"}},
+		{name: "shouting", text: "THIS IS THE BEST MOVIE EVER MADE", wantReasons: []string{"mostly capitals"}},
+		{name: "repeated words", text: "buy buy buy buy buy buy buy buy buy buy", wantReasons: []string{"repeated words"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := heuristicScore(tt.text)
+			if !slices.Equal(result.Reasons, tt.wantReasons) {
+				t.Errorf("heuristicScore(%q) reasons = %q, want %q", tt.text, result.Reasons, tt.wantReasons)
+			}
+			if len(tt.wantReasons) == 0 && result.Score != 0 {
+				t.Errorf("heuristicScore(%q) score = %v, want 0", tt.text, result.Score)
+			}
+			if len(tt.wantReasons) > 0 && (result.Score <= 0 || result.Score > 1) {
+				t.Errorf("heuristicScore(%q) score = %v, want within (0, 1]", tt.text, result.Score)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	config := Config{HideThreshold: 0.7, ReviewThreshold: 0.3, ReportThreshold: 3}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"A fine film.", models.ReviewPublished},
+		{"call +1 555 123 4567", models.ReviewFlagged},
+		{"shit shit shit, more at www.spam.com and www.spam.net", models.ReviewHidden},
+	}
+
+	for _, tt := range tests {
+		if got := config.Status(config.Score(context.Background(), tt.text)); got != tt.want {
+			t.Errorf("Status(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
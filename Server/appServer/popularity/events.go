@@ -0,0 +1,62 @@
+package popularity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// how much each kind of engagement counts; a rating counts in proportion
+// to its stars, so a one-star rating adds nothing
+var eventWeights = map[string]float64{
+	models.EventView:         1,
+	models.EventWatchlistAdd: 3,
+	models.EventRating:       4,
+}
+
+// EventRetention is how long events are kept, the longest window.
+const EventRetention = 30 * 24 * time.Hour
+
+// Record stores an engagement event. Repeats of the same kind by the same
+// user on the same movie and day are ignored.
+func Record(ctx context.Context, client *mongo.Client, eventType, imdbID, userID string) error {
+	return record(ctx, client, eventType, imdbID, userID, eventWeights[eventType])
+}
+
+// RecordRating stores a rating event weighted by its stars.
+func RecordRating(ctx context.Context, client *mongo.Client, imdbID, userID string, rating int) error {
+	weight := eventWeights[models.EventRating] * float64(rating-1) / 4
+	return record(ctx, client, models.EventRating, imdbID, userID, weight)
+}
+
+func record(ctx context.Context, client *mongo.Client, eventType, imdbID, userID string, weight float64) error {
+	now := time.Now()
+	id := fmt.Sprintf("%s:%s:%s:%s", eventType, imdbID, userID, now.UTC().Format(time.DateOnly))
+
+	_, err := events(client).UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$setOnInsert": bson.M{
+			"type":        eventType,
+			"imdb_id":     imdbID,
+			"user_id":     userID,
+			"weight":      weight,
+			"occurred_at": now,
+		}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	// a concurrent request recorded the same event first
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func events(client *mongo.Client) *mongo.Collection {
+	return database.OpenCollection("movie_events", client)
+}
@@ -0,0 +1,274 @@
+package popularity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Windows are the rolling windows feeds are built over.
+var Windows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": EventRetention,
+}
+
+// Within a window an event's weight halves every window/popularHalfLives
+// for the popular feed, so popular rewards steady engagement across the
+// window. Trending is growth instead: the weight of the window's later
+// half minus that of its earlier half, so only movies gaining engagement
+// make the trending feed.
+const (
+	popularHalfLives = 1
+	// entries kept per feed
+	feedSize       = 100
+	writeBatchSize = 500
+)
+
+var ErrUnknownWindow = errors.New("window must be 24h, 7d or 30d")
+
+type movieScore struct {
+	ImdbID  string  `bson:"_id"`
+	Popular float64 `bson:"popular"`
+	// summed event weights in the later and earlier half of the window
+	Recent  float64 `bson:"recent"`
+	Earlier float64 `bson:"earlier"`
+	Events  int64   `bson:"events"`
+}
+
+// Trending is how much the movie's engagement grew from the earlier half
+// of the window to the later one.
+func (score movieScore) Trending() float64 {
+	return score.Recent - score.Earlier
+}
+
+// Aggregate rebuilds every feed from the events in each window, overall
+// and per genre, and returns how many feeds were written. Feeds for
+// genres with no events left are removed.
+func Aggregate(ctx context.Context, client *mongo.Client) (int, error) {
+	started := time.Now()
+
+	var feeds []models.MovieFeed
+	for window, length := range Windows {
+		scores, err := windowScores(ctx, client, started, length)
+		if err != nil {
+			return 0, err
+		}
+		windowFeeds, err := buildFeeds(ctx, client, window, scores, started)
+		if err != nil {
+			return 0, err
+		}
+		feeds = append(feeds, windowFeeds...)
+	}
+
+	collection := database.OpenCollection("movie_feeds", client)
+	var writes []mongo.WriteModel
+	for i, feed := range feeds {
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": feed.ID}).
+			SetReplacement(feed).
+			SetUpsert(true))
+		if len(writes) == writeBatchSize || i == len(feeds)-1 {
+			if _, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+				return 0, err
+			}
+			writes = writes[:0]
+		}
+	}
+
+	if _, err := collection.DeleteMany(ctx, bson.M{"computed_at": bson.M{"$lt": started}}); err != nil {
+		return len(feeds), err
+	}
+	return len(feeds), nil
+}
+
+// windowScores sums each movie's event weights over the window, decayed
+// by age for the popular feed and split at the window's midpoint for
+// trending.
+func windowScores(ctx context.Context, client *mongo.Client, now time.Time, length time.Duration) ([]movieScore, error) {
+	// weight * e^(-ln2 * age / half-life); the subtraction gives a negative
+	// age in milliseconds
+	perMilli := math.Ln2 * popularHalfLives / float64(length.Milliseconds())
+	decayed := bson.M{"$sum": bson.M{"$multiply": bson.A{
+		"$weight",
+		bson.M{"$exp": bson.M{"$multiply": bson.A{perMilli, bson.M{"$subtract": bson.A{"$occurred_at", now}}}}},
+	}}}
+
+	midpoint := now.Add(-length / 2)
+	half := func(recent bool) bson.M {
+		inHalf := bson.M{"$gte": bson.A{"$occurred_at", midpoint}}
+		if !recent {
+			inHalf = bson.M{"$lt": bson.A{"$occurred_at", midpoint}}
+		}
+		return bson.M{"$sum": bson.M{"$cond": bson.A{inHalf, "$weight", 0}}}
+	}
+
+	cursor, err := events(client).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"occurred_at": bson.M{"$gte": now.Add(-length)}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     "$imdb_id",
+			"popular": decayed,
+			"recent":  half(true),
+			"earlier": half(false),
+			"events":  bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var scores []movieScore
+	err = cursor.All(ctx, &scores)
+	return scores, err
+}
+
+// buildFeeds ranks the scored movies that still exist into the window's
+// trending and popular feeds, overall and for every genre.
+func buildFeeds(ctx context.Context, client *mongo.Client, window string, scores []movieScore, now time.Time) ([]models.MovieFeed, error) {
+	ids := make([]string, len(scores))
+	for i, score := range scores {
+		ids[i] = score.ImdbID
+	}
+
+	cursor, err := database.OpenCollection("movies", client).Find(ctx,
+		bson.M{"imdb_id": bson.M{"$in": ids}, "deleted_at": nil},
+		options.Find().SetProjection(bson.M{"imdb_id": 1, "genre": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var movies []models.Movie
+	if err := cursor.All(ctx, &movies); err != nil {
+		return nil, err
+	}
+	genres := map[string][]models.Genre{}
+	for _, movie := range movies {
+		genres[movie.ImdbID] = movie.Genre
+	}
+
+	byGenre := map[string][]movieScore{"": nil}
+	for _, score := range scores {
+		movieGenres, ok := genres[score.ImdbID]
+		if !ok {
+			continue
+		}
+		byGenre[""] = append(byGenre[""], score)
+		for _, genre := range movieGenres {
+			byGenre[genre.GenreName] = append(byGenre[genre.GenreName], score)
+		}
+	}
+
+	var feeds []models.MovieFeed
+	for genre, genreScores := range byGenre {
+		for _, kind := range []string{models.FeedTrending, models.FeedPopular} {
+			feeds = append(feeds, models.MovieFeed{
+				ID:         feedID(kind, window, genre),
+				Kind:       kind,
+				Window:     window,
+				Genre:      genre,
+				Entries:    topEntries(kind, genreScores),
+				ComputedAt: now,
+			})
+		}
+	}
+	return feeds, nil
+}
+
+// topEntries ranks scores for the feed kind. Movies whose engagement did
+// not grow are left out of the trending feed.
+func topEntries(kind string, scores []movieScore) []models.FeedEntry {
+	entries := make([]models.FeedEntry, 0, len(scores))
+	for _, score := range scores {
+		entry := models.FeedEntry{ImdbID: score.ImdbID, Score: score.Popular, Events: score.Events}
+		if kind == models.FeedTrending {
+			if entry.Score = score.Trending(); entry.Score <= 0 {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].ImdbID < entries[j].ImdbID
+	})
+	return entries[:min(len(entries), feedSize)]
+}
+
+// Feed returns up to limit movies of a feed, best first. A feed that has
+// not been built yet, or a genre without events, is empty.
+func Feed(ctx context.Context, client *mongo.Client, kind, window, genre string, limit int64) (models.MovieFeed, error) {
+	feed := models.MovieFeed{Kind: kind, Window: window, Genre: genre, Movies: []models.Movie{}}
+	if _, ok := Windows[window]; !ok {
+		return feed, ErrUnknownWindow
+	}
+
+	err := database.OpenCollection("movie_feeds", client).FindOne(ctx, bson.M{"_id": feedID(kind, window, genre)}).Decode(&feed)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return feed, nil
+	}
+	if err != nil {
+		return feed, err
+	}
+
+	ids := make([]string, 0, len(feed.Entries))
+	for _, entry := range feed.Entries {
+		ids = append(ids, entry.ImdbID)
+	}
+	cursor, err := database.OpenCollection("movies", client).Find(ctx,
+		bson.M{"imdb_id": bson.M{"$in": ids}, "deleted_at": nil},
+		options.Find().SetProjection(bson.M{"embedding": 0}),
+	)
+	if err != nil {
+		return feed, err
+	}
+	var found []models.Movie
+	if err := cursor.All(ctx, &found); err != nil {
+		return feed, err
+	}
+	byID := map[string]models.Movie{}
+	for _, movie := range found {
+		byID[movie.ImdbID] = movie
+	}
+
+	feed.Movies = []models.Movie{}
+	for _, id := range ids {
+		if movie, ok := byID[id]; ok && int64(len(feed.Movies)) < limit {
+			feed.Movies = append(feed.Movies, movie)
+		}
+	}
+	return feed, nil
+}
+
+// TrendingIDs returns the movies in the top limit of a trending feed.
+func TrendingIDs(ctx context.Context, client *mongo.Client, window string, limit int) (map[string]bool, error) {
+	var feed models.MovieFeed
+	err := database.OpenCollection("movie_feeds", client).FindOne(ctx, bson.M{"_id": feedID(models.FeedTrending, window, "")}).Decode(&feed)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[string]bool{}
+	for _, entry := range feed.Entries[:min(len(feed.Entries), limit)] {
+		ids[entry.ImdbID] = true
+	}
+	return ids, nil
+}
+
+func feedID(kind, window, genre string) string {
+	return fmt.Sprintf("%s:%s:%s", kind, window, genre)
+}
@@ -0,0 +1,107 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// attempts at claiming the next version number when saves race
+const maxSaveAttempts = 5
+
+var (
+	ErrTemplateNotFound = errors.New("prompt template not found")
+	ErrVersionNotFound  = errors.New("prompt template version not found")
+)
+
+// Current returns the newest version of the named template.
+func Current(ctx context.Context, client *mongo.Client, name string) (models.PromptTemplate, error) {
+	var template models.PromptTemplate
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+	err := collection(client).FindOne(ctx, bson.M{"name": name}, opts).Decode(&template)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return template, ErrTemplateNotFound
+	}
+	return template, err
+}
+
+// Version returns one version of the named template.
+func Version(ctx context.Context, client *mongo.Client, name string, version int) (models.PromptTemplate, error) {
+	var template models.PromptTemplate
+	err := collection(client).FindOne(ctx, bson.M{"name": name, "version": version}).Decode(&template)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return template, ErrVersionNotFound
+	}
+	return template, err
+}
+
+// Versions lists every version of the named template, newest first.
+func Versions(ctx context.Context, client *mongo.Client, name string) ([]models.PromptTemplate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: -1}})
+	cursor, err := collection(client).Find(ctx, bson.M{"name": name}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []models.PromptTemplate{}
+	if err := cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Save stores body as the next version of the named template, which makes
+// it the one in use.
+func Save(ctx context.Context, client *mongo.Client, template models.PromptTemplate) (models.PromptTemplate, error) {
+	if err := Validate(template.Body); err != nil {
+		return template, err
+	}
+
+	// the unique (name, version) index turns a concurrent save into a
+	// duplicate key error; take the next number and try again
+	for attempt := 1; ; attempt++ {
+		latest, err := Current(ctx, client, template.Name)
+		if err != nil && !errors.Is(err, ErrTemplateNotFound) {
+			return template, err
+		}
+
+		template.ID = bson.NewObjectID()
+		template.Version = latest.Version + 1
+		template.CreatedAt = time.Now()
+
+		_, err = collection(client).InsertOne(ctx, template)
+		if err == nil {
+			return template, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) || attempt == maxSaveAttempts {
+			return template, err
+		}
+	}
+}
+
+// Rollback saves the body of an earlier version as a new version, so the
+// history stays append-only.
+func Rollback(ctx context.Context, client *mongo.Client, name string, version int, createdBy string) (models.PromptTemplate, error) {
+	previous, err := Version(ctx, client, name, version)
+	if err != nil {
+		return previous, err
+	}
+
+	return Save(ctx, client, models.PromptTemplate{
+		Name:           name,
+		Body:           previous.Body,
+		Note:           previous.Note,
+		RolledBackFrom: previous.Version,
+		CreatedBy:      createdBy,
+	})
+}
+
+func collection(client *mongo.Client) *mongo.Collection {
+	return database.OpenCollection("prompt_templates", client)
+}
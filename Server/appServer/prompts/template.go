@@ -0,0 +1,107 @@
+package prompts
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// names of the templates the server uses
+const (
+	// ReviewRanking ranks admin reviews.
+	ReviewRanking = "review_ranking"
+	// MovieCopy drafts a movie's synopsis and tagline.
+	MovieCopy = "movie_copy"
+)
+
+// PlaceholderType says how a value is written into a template.
+type PlaceholderType string
+
+const (
+	// TextPlaceholder is replaced by a single string.
+	TextPlaceholder PlaceholderType = "text"
+	// ListPlaceholder is replaced by its values joined with commas.
+	ListPlaceholder PlaceholderType = "list"
+)
+
+// Placeholders are the names a template may use, written as {name}.
+var Placeholders = map[string]PlaceholderType{
+	"rankings": ListPlaceholder,
+	"title":    TextPlaceholder,
+	"genres":   ListPlaceholder,
+	"review":   TextPlaceholder,
+}
+
+// only lowercase names count as placeholders, so JSON examples such as
+// {"ranking": "Good"} pass through untouched
+var placeholder = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// Vars are the values substituted into a template.
+type Vars struct {
+	Rankings []string
+	Title    string
+	Genres   []string
+	Review   string
+}
+
+func (v Vars) lookup(name string) (text string, list []string) {
+	switch name {
+	case "rankings":
+		return "", v.Rankings
+	case "genres":
+		return "", v.Genres
+	case "title":
+		return v.Title, nil
+	case "review":
+		return v.Review, nil
+	}
+	return "", nil
+}
+
+// Validate reports placeholders in body that are not in Placeholders.
+func Validate(body string) error {
+	var unknown []string
+	for _, match := range placeholder.FindAllStringSubmatch(body, -1) {
+		if _, ok := Placeholders[match[1]]; !ok {
+			unknown = append(unknown, match[0])
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown placeholders %s; allowed are %s", strings.Join(unknown, ", "), allowedNames())
+	}
+	return nil
+}
+
+// Render substitutes vars into body. A template without {review} gets the
+// review appended, which is how the BASE_PROMPT_TEMPLATE prompts were
+// written.
+func Render(body string, vars Vars) string {
+	rendered := placeholder.ReplaceAllStringFunc(body, func(match string) string {
+		name := match[1 : len(match)-1]
+		switch Placeholders[name] {
+		case TextPlaceholder:
+			text, _ := vars.lookup(name)
+			return text
+		case ListPlaceholder:
+			_, list := vars.lookup(name)
+			return strings.Join(list, ",")
+		}
+		return match
+	})
+
+	if !strings.Contains(body, "{review}") {
+		rendered += vars.Review
+	}
+	return rendered
+}
+
+func allowedNames() string {
+	var names []string
+	for name := range Placeholders {
+		names = append(names, "{"+name+"}")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
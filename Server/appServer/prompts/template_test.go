@@ -0,0 +1,79 @@
+package prompts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "no placeholders", body: "Rank this review:"},
+		{name: "known placeholders", body: "Rank {review} of {title} ({genres}) as one of {rankings}"},
+		{name: "json example", body: `Answer like {"ranking": "Good"} or {Ranking}`},
+		{name: "unknown placeholder", body: "Rank {review} by {director}", wantErr: "unknown placeholders {director}"},
+		{name: "unknown placeholders sorted", body: "{year} {cast} {review}", wantErr: "unknown placeholders {cast}, {year}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.body)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate(%q) error: %v", tt.body, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate(%q) = %v, want an error containing %q", tt.body, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	vars := Vars{
+		Rankings: []string{"Excellent", "Good", "Bad"},
+		Title:    "Alien",
+		Genres:   []string{"Horror", "Sci-Fi"},
+		Review:   "Tense and terrifying.",
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "all placeholders",
+			body: "{title} [{genres}] pick one of {rankings}: {review}",
+			want: "Alien [Horror,Sci-Fi] pick one of Excellent,Good,Bad: Tense and terrifying.",
+		},
+		{
+			name: "review appended when missing",
+			body: "Pick one of {rankings}: ",
+			want: "Pick one of Excellent,Good,Bad: Tense and terrifying.",
+		},
+		{
+			name: "unknown and json placeholders kept",
+			body: `{director} {"ranking": "Good"} {review}`,
+			want: `{director} {"ranking": "Good"} Tense and terrifying.`,
+		},
+		{
+			name: "repeated placeholder",
+			body: "{title}, {title}: {review}",
+			want: "Alien, Alien: Tense and terrifying.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.body, vars); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
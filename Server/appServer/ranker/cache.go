@@ -0,0 +1,147 @@
+package ranker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const defaultCacheTTL = 30 * 24 * time.Hour
+
+// Cache stores parseable ranker answers in the ranking_cache collection so
+// the same review, prompt and model is only paid for once. Entries expire
+// after the TTL through a TTL index.
+type Cache struct {
+	client *mongo.Client
+	ttl    time.Duration
+}
+
+func NewCache(client *mongo.Client, ttl time.Duration) *Cache {
+	return &Cache{client: client, ttl: ttl}
+}
+
+// CacheFromEnv returns a cache with the RANKING_CACHE_TTL duration (30
+// days by default), or nil when RANKING_CACHE_TTL is "0" or "off".
+func CacheFromEnv(client *mongo.Client) *Cache {
+	ttl := defaultCacheTTL
+	switch raw := os.Getenv("RANKING_CACHE_TTL"); raw {
+	case "":
+	case "0", "off":
+		return nil
+	default:
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			log.Printf("Invalid RANKING_CACHE_TTL %q, using %s", raw, defaultCacheTTL)
+		} else {
+			ttl = parsed
+		}
+	}
+	return NewCache(client, ttl)
+}
+
+// CacheKey addresses an answer by the prompt template version, the model
+// and the review. The rendered prompt is hashed in as well, since it also
+// carries the ranking scale and any movie fields the template uses.
+func CacheKey(promptVersion int, model, review, prompt string) string {
+	hash := sha256.New()
+	for _, part := range []string{strconv.Itoa(promptVersion), model, review, prompt} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the cached answer for key, if there is one that has not
+// expired yet.
+func (c *Cache) Get(ctx context.Context, key string) (RankResult, bool, error) {
+	var entry models.RankingCacheEntry
+	err := c.collection().FindOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$gt": time.Now()}}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return RankResult{}, false, nil
+	}
+	if err != nil {
+		return RankResult{}, false, err
+	}
+	return RankResult{Response: entry.Response, Model: entry.Model}, true, nil
+}
+
+func (c *Cache) Put(ctx context.Context, key string, promptVersion int, result RankResult) error {
+	now := time.Now()
+	_, err := c.collection().ReplaceOne(ctx, bson.M{"_id": key}, models.RankingCacheEntry{
+		Key:           key,
+		PromptVersion: promptVersion,
+		Model:         result.Model,
+		Response:      result.Response,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(c.ttl),
+	}, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Purge deletes cached answers, limited to one model and prompt version
+// when those are given, and returns how many were removed.
+func (c *Cache) Purge(ctx context.Context, model string, promptVersion *int) (int64, error) {
+	filter := bson.M{}
+	if model != "" {
+		filter["model"] = model
+	}
+	if promptVersion != nil {
+		filter["prompt_version"] = *promptVersion
+	}
+
+	result, err := c.collection().DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (c *Cache) collection() *mongo.Collection {
+	return database.OpenCollection("ranking_cache", c.client)
+}
+
+// RankReviewCached is RankReview behind the cache. A cached answer is
+// parsed again against the current scale and ignored if it no longer
+// matches. Only answers from the ranker's own model are stored, so a
+// fallback answer is never served in place of the primary model. A nil
+// cache ranks directly; cache failures are logged and ranking goes on.
+func RankReviewCached(ctx context.Context, cache *Cache, reviewRanker ReviewRanker, req RankRequest, promptVersion, maxAttempts int) (models.Ranking, RankResult, bool, error) {
+	if cache == nil {
+		ranking, result, err := RankReview(ctx, reviewRanker, req, maxAttempts)
+		return ranking, result, false, err
+	}
+
+	key := CacheKey(promptVersion, reviewRanker.Model(), req.Review, req.Prompt)
+	cached, ok, err := cache.Get(ctx, key)
+	if err != nil {
+		log.Println("Error reading ranking cache:", err)
+	}
+	if ok {
+		if ranking, err := ParseRanking(cached.Response, req.Rankings); err == nil {
+			return ranking, cached, true, nil
+		}
+	}
+
+	ranking, result, err := RankReview(ctx, reviewRanker, req, maxAttempts)
+	if err != nil {
+		return ranking, result, false, err
+	}
+
+	if result.Model == reviewRanker.Model() {
+		if err := cache.Put(ctx, key, promptVersion, result); err != nil {
+			log.Println("Error writing ranking cache:", err)
+		}
+	}
+	return ranking, result, false, nil
+}
@@ -0,0 +1,30 @@
+package ranker
+
+import "testing"
+
+func TestCacheKey(t *testing.T) {
+	base := CacheKey(1, "gpt-4o-mini", "Tense and terrifying.", "Rank this review:")
+	if len(base) != 64 {
+		t.Fatalf("CacheKey length = %d, want a hex sha256 of 64", len(base))
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		same bool
+	}{
+		{"same inputs", CacheKey(1, "gpt-4o-mini", "Tense and terrifying.", "Rank this review:"), true},
+		{"new prompt version", CacheKey(2, "gpt-4o-mini", "Tense and terrifying.", "Rank this review:"), false},
+		{"other model", CacheKey(1, "llama3", "Tense and terrifying.", "Rank this review:"), false},
+		{"edited review", CacheKey(1, "gpt-4o-mini", "Tense and terrifying!", "Rank this review:"), false},
+		{"other prompt", CacheKey(1, "gpt-4o-mini", "Tense and terrifying.", "Rank this review"), false},
+		// parts are separated, so text cannot slide from one part to the next
+		{"shifted boundary", CacheKey(1, "gpt-4o-mini", "Tense and terrifying.Rank", " this review:"), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.key == base; got != tt.same {
+			t.Errorf("%s: key equal to base = %v, want %v", tt.name, got, tt.same)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package ranker
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+)
+
+var positiveWords = map[string]bool{
+	"amazing": true, "beautiful": true, "best": true, "brilliant": true, "captivating": true,
+	"charming": true, "classic": true, "compelling": true, "delightful": true, "enjoyable": true,
+	"entertaining": true, "excellent": true, "exceptional": true, "fantastic": true, "fun": true,
+	"funny": true, "gem": true, "good": true, "gorgeous": true, "gripping": true,
+	"great": true, "hilarious": true, "impressive": true, "love": true, "loved": true,
+	"masterpiece": true, "memorable": true, "moving": true, "outstanding": true, "perfect": true,
+	"powerful": true, "recommend": true, "riveting": true, "solid": true, "stunning": true,
+	"superb": true, "thrilling": true, "touching": true, "wonderful": true,
+}
+
+var negativeWords = map[string]bool{
+	"awful": true, "bad": true, "boring": true, "clumsy": true, "confusing": true,
+	"disappointing": true, "disappointment": true, "dull": true, "forgettable": true, "hate": true,
+	"hated": true, "horrible": true, "lazy": true, "mediocre": true, "mess": true,
+	"messy": true, "poor": true, "predictable": true, "ridiculous": true, "shallow": true,
+	"slow": true, "stupid": true, "tedious": true, "terrible": true, "tiresome": true,
+	"unwatchable": true, "waste": true, "weak": true, "worst": true,
+}
+
+var negations = map[string]bool{
+	"not": true, "no": true, "never": true, "hardly": true, "isn't": true, "wasn't": true,
+	"don't": true, "didn't": true, "doesn't": true, "nothing": true,
+}
+
+type keywordRanker struct{}
+
+// NewKeyword returns a deterministic ranker that scores the review against
+// small positive and negative word lists. It needs no network access, which
+// makes it the ranker for offline development and CI.
+func NewKeyword() ReviewRanker {
+	return keywordRanker{}
+}
+
+func (keywordRanker) Model() string {
+	return ProviderKeyword
+}
+
+// Rank maps the review's sentiment, from -1 to 1, evenly onto the ranked
+// levels: the most positive review gets the best (lowest value) level.
+func (keywordRanker) Rank(ctx context.Context, req RankRequest) (RankResult, error) {
+	var levels []models.Ranking
+	for _, ranking := range req.Rankings {
+		if !ranking.Unranked && !ranking.Retired {
+			levels = append(levels, ranking)
+		}
+	}
+	if len(levels) == 0 {
+		return RankResult{}, errors.New("no ranking levels to choose from")
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].RankingValue < levels[j].RankingValue
+	})
+
+	score := sentiment(req.Review)
+	index := int(math.Round((1 - score) / 2 * float64(len(levels)-1)))
+
+	return RankResult{Response: levels[index].RankingName, Model: ProviderKeyword}, nil
+}
+
+// sentiment scores text from -1 (all negative) to 1 (all positive). A
+// negation flips the next sentiment word within three words of it.
+func sentiment(text string) float64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+
+	positive, negative := 0, 0
+	negateUntil := -1
+	for i, word := range words {
+		if negations[word] {
+			negateUntil = i + 3
+			continue
+		}
+
+		polarity := 0
+		if positiveWords[word] {
+			polarity = 1
+		} else if negativeWords[word] {
+			polarity = -1
+		}
+		if polarity == 0 {
+			continue
+		}
+		if i <= negateUntil {
+			polarity = -polarity
+			negateUntil = -1
+		}
+
+		if polarity > 0 {
+			positive++
+		} else {
+			negative++
+		}
+	}
+
+	if positive+negative == 0 {
+		return 0
+	}
+	return float64(positive-negative) / float64(positive+negative)
+}
@@ -0,0 +1,63 @@
+package ranker
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSentiment(t *testing.T) {
+	tests := []struct {
+		text string
+		want float64
+	}{
+		{"", 0},
+		{"a movie about a dog", 0},
+		{"a brilliant, gripping masterpiece", 1},
+		{"boring and predictable", -1},
+		{"great cast but a boring plot", 0},
+		{"not good", -1},
+		{"it wasn't boring at all", 1},
+		{"never a dull moment, simply superb", 1},
+		// a negation only reaches three words ahead
+		{"not that I expected it to be good", 1},
+	}
+
+	for _, tt := range tests {
+		if got := sentiment(tt.text); got != tt.want {
+			t.Errorf("sentiment(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestKeywordRank(t *testing.T) {
+	tests := []struct {
+		review string
+		want   string
+	}{
+		{"An outstanding, moving masterpiece.", "Excellent"},
+		{"Great fun, though the ending was weak and a bit slow.", "Okay"},
+		{"Nothing to say.", "Okay"},
+		{"Dull, tedious and a waste of time.", "Terrible"},
+		{"Good acting, great score, but a boring script.", "Good"},
+	}
+
+	for _, tt := range tests {
+		result, err := NewKeyword().Rank(context.Background(), RankRequest{Review: tt.review, Rankings: testRankings})
+		if err != nil {
+			t.Fatalf("Rank(%q) error: %v", tt.review, err)
+		}
+		if result.Response != tt.want {
+			t.Errorf("Rank(%q) = %q, want %q", tt.review, result.Response, tt.want)
+		}
+		if result.Model != ProviderKeyword {
+			t.Errorf("Rank(%q) model = %q, want %q", tt.review, result.Model, ProviderKeyword)
+		}
+	}
+}
+
+func TestKeywordRankWithoutLevels(t *testing.T) {
+	_, err := NewKeyword().Rank(context.Background(), RankRequest{Review: "good", Rankings: testRankings[5:]})
+	if err == nil {
+		t.Fatal("Rank with only the unranked level returned no error")
+	}
+}
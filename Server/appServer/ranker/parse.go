@@ -0,0 +1,185 @@
+package ranker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/search"
+)
+
+// DefaultMaxAttempts bounds how often a ranker is asked again after an
+// answer that matches none of the rankings.
+const DefaultMaxAttempts = 3
+
+// minimum similarity for a misspelt answer to count as a ranking name
+const fuzzyMatchThreshold = 0.8
+
+// ErrUnparseableResponse is returned when no attempt produced an answer
+// that maps onto a known ranking.
+var ErrUnparseableResponse = errors.New("ranker response does not match any ranking")
+
+var jsonObject = regexp.MustCompile(`(?s)\{.*?\}`)
+
+// StructuredOutputInstructions asks an LLM to answer with a JSON object
+// naming exactly one of the ranked levels.
+func StructuredOutputInstructions(rankings []models.Ranking) string {
+	return fmt.Sprintf(
+		"\n\nRespond with only a JSON object of the form {\"ranking\": \"<name>\"} where <name> is exactly one of: %s. Do not add any explanation.",
+		strings.Join(rankedNames(rankings), ", "),
+	)
+}
+
+// RankReview asks reviewRanker for a ranking and parses its answer,
+// re-prompting up to maxAttempts times when the answer cannot be matched.
+// The returned RankResult is the last raw answer received.
+func RankReview(ctx context.Context, reviewRanker ReviewRanker, req RankRequest, maxAttempts int) (models.Ranking, RankResult, error) {
+	basePrompt := req.Prompt + StructuredOutputInstructions(req.Rankings)
+	req.Prompt = basePrompt
+
+	var result RankResult
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var err error
+		result, err = reviewRanker.Rank(ctx, req)
+		if err != nil {
+			return models.Ranking{}, result, err
+		}
+
+		ranking, err := ParseRanking(result.Response, req.Rankings)
+		if err == nil {
+			return ranking, result, nil
+		}
+
+		req.Prompt = basePrompt + fmt.Sprintf(
+			"\n\nYour previous reply %q did not name one of the allowed rankings. Reply again with only the JSON object.",
+			truncate(result.Response, 200),
+		)
+	}
+
+	return models.Ranking{}, result, fmt.Errorf("%w after %d attempts: %q", ErrUnparseableResponse, maxAttempts, truncate(result.Response, 200))
+}
+
+// ParseRanking maps a raw answer onto one of the ranked levels. It accepts
+// a JSON object with a "ranking" field, a bare ranking name in any case or
+// surrounded by punctuation, an explanation that names exactly one ranking,
+// or a near miss spelling of a ranking name.
+func ParseRanking(response string, rankings []models.Ranking) (models.Ranking, error) {
+	var candidates []models.Ranking
+	for _, ranking := range rankings {
+		if !ranking.Unranked && !ranking.Retired {
+			candidates = append(candidates, ranking)
+		}
+	}
+
+	answer := response
+	if match := jsonObject.FindString(response); match != "" {
+		var structured struct {
+			Ranking string `json:"ranking"`
+		}
+		if err := json.Unmarshal([]byte(match), &structured); err == nil && structured.Ranking != "" {
+			answer = structured.Ranking
+		}
+	}
+	normalized := normalize(answer)
+	if normalized == "" {
+		return models.Ranking{}, ErrUnparseableResponse
+	}
+
+	for _, ranking := range candidates {
+		if normalize(ranking.RankingName) == normalized {
+			return ranking, nil
+		}
+	}
+
+	// an explanation that mentions exactly one ranking name as a word; a
+	// negated mention such as "not good" names no ranking
+	var mentioned []models.Ranking
+	negated := false
+	words := strings.Fields(normalized)
+	for _, ranking := range candidates {
+		switch mentions(words, strings.Fields(normalize(ranking.RankingName))) {
+		case mentionPlain:
+			mentioned = append(mentioned, ranking)
+		case mentionNegated:
+			negated = true
+		}
+	}
+	if len(mentioned) == 1 {
+		return mentioned[0], nil
+	}
+	if negated {
+		return models.Ranking{}, ErrUnparseableResponse
+	}
+
+	best, bestScore := models.Ranking{}, 0.0
+	for _, ranking := range candidates {
+		if score := search.Similarity(normalize(ranking.RankingName), normalized); score > bestScore {
+			best, bestScore = ranking, score
+		}
+	}
+	if bestScore >= fuzzyMatchThreshold {
+		return best, nil
+	}
+
+	return models.Ranking{}, ErrUnparseableResponse
+}
+
+// words that negate the ranking name after them, as normalized; "t" is
+// what is left of "isn't" or "wasn't"
+var parseNegations = map[string]bool{
+	"not": true, "no": true, "never": true, "hardly": true, "t": true,
+}
+
+const (
+	mentionNone = iota
+	mentionPlain
+	mentionNegated
+)
+
+// mentions reports whether name occurs as a run of words in words, and
+// whether every occurrence follows a negation.
+func mentions(words, name []string) int {
+	found := mentionNone
+	for i := 0; len(name) > 0 && i+len(name) <= len(words); i++ {
+		if !slices.Equal(words[i:i+len(name)], name) {
+			continue
+		}
+		if i > 0 && parseNegations[words[i-1]] {
+			found = mentionNegated
+			continue
+		}
+		return mentionPlain
+	}
+	return found
+}
+
+// normalize lowercases s and collapses everything but letters and digits
+// into single spaces, so "Good." and " good\n" compare equal.
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+func rankedNames(rankings []models.Ranking) []string {
+	var names []string
+	for _, ranking := range rankings {
+		if !ranking.Unranked && !ranking.Retired {
+			names = append(names, ranking.RankingName)
+		}
+	}
+	return names
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,57 @@
+package ranker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+)
+
+var testRankings = []models.Ranking{
+	{RankingValue: 1, RankingName: "Excellent"},
+	{RankingValue: 2, RankingName: "Good"},
+	{RankingValue: 3, RankingName: "Okay"},
+	{RankingValue: 4, RankingName: "Bad"},
+	{RankingValue: 5, RankingName: "Terrible"},
+	{RankingValue: 999, RankingName: "Not Ranked", Unranked: true},
+}
+
+func TestParseRanking(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"bare name", "Good", "Good"},
+		{"case and punctuation", "  excellent.\n", "Excellent"},
+		{"json object", `{"ranking": "Terrible"}`, "Terrible"},
+		{"json in prose", `Sure! {"ranking": "okay"} hope that helps`, "Okay"},
+		{"explanation naming one ranking", "I would call this review Bad overall", "Bad"},
+		{"misspelt name", "Excelent", "Excellent"},
+		{"negation before another ranking", "not good, I'd say bad", "Bad"},
+		{"unranked level is not a candidate", "Not Ranked", ""},
+		{"negated ranking", "not good", ""},
+		{"contracted negation", "it isn't excellent", ""},
+		{"two rankings", "somewhere between good and okay", ""},
+		{"no ranking", "I cannot rank this", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranking, err := ParseRanking(tt.response, testRankings)
+			if tt.want == "" {
+				if !errors.Is(err, ErrUnparseableResponse) {
+					t.Fatalf("ParseRanking(%q) = %q, %v; want ErrUnparseableResponse", tt.response, ranking.RankingName, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRanking(%q) error: %v", tt.response, err)
+			}
+			if ranking.RankingName != tt.want {
+				t.Errorf("ParseRanking(%q) = %q, want %q", tt.response, ranking.RankingName, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package ranker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/ollama"
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+const (
+	ProviderOpenAI  = "openai"
+	ProviderOllama  = "ollama"
+	ProviderKeyword = "keyword"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.groq.com/openai/v1"
+	defaultOpenAIModel   = "llama-3.1-8b-instant"
+	defaultOllamaURL     = "http://localhost:11434"
+	defaultOllamaModel   = "llama3.1"
+)
+
+// RankRequest is everything a ranker may use to rank an admin review.
+type RankRequest struct {
+	// Prompt is the rendered prompt, review included, for LLM rankers.
+	Prompt string
+	// Review is the raw admin review text.
+	Review string
+	// Rankings are the levels the answer must be one of.
+	Rankings []models.Ranking
+}
+
+// RankResult is a provider's raw answer and the model that produced it.
+type RankResult struct {
+	Response string
+	Model    string
+}
+
+// ReviewRanker picks a ranking name for an admin review.
+type ReviewRanker interface {
+	// Model identifies the provider and model, for example "openai/gpt-4o".
+	Model() string
+	Rank(ctx context.Context, req RankRequest) (RankResult, error)
+}
+
+// Generator writes free text from a prompt with an LLM.
+type Generator interface {
+	Model() string
+	Generate(ctx context.Context, prompt string) (RankResult, error)
+}
+
+// FromEnv builds the ranker selected by REVIEW_RANKER (openai, ollama or
+// keyword; openai by default). With REVIEW_RANKER_FALLBACK=true an LLM
+// ranker falls back to the keyword ranker whenever the provider fails, and
+// the keyword ranker is used outright when the LLM ranker cannot be built,
+// for example because no API key is configured.
+func FromEnv() (ReviewRanker, error) {
+	provider := strings.ToLower(os.Getenv("REVIEW_RANKER"))
+	if provider == ProviderKeyword {
+		return NewKeyword(), nil
+	}
+	fallback := os.Getenv("REVIEW_RANKER_FALLBACK") == "true"
+
+	reviewRanker, err := llmFromEnv(provider)
+	if err != nil {
+		if fallback {
+			log.Printf("Review ranker %q unavailable, using the keyword ranker: %v", provider, err)
+			return NewKeyword(), nil
+		}
+		return nil, err
+	}
+
+	if fallback {
+		return WithFallback(reviewRanker, NewKeyword()), nil
+	}
+	return reviewRanker, nil
+}
+
+// GeneratorFromEnv returns the LLM configured for ranking, for prompts
+// that need written text rather than a ranking. The keyword ranker cannot
+// write, so it is an error when REVIEW_RANKER=keyword.
+func GeneratorFromEnv() (Generator, error) {
+	provider := strings.ToLower(os.Getenv("REVIEW_RANKER"))
+	if provider == ProviderKeyword {
+		return nil, errors.New("REVIEW_RANKER=keyword has no LLM to generate text with")
+	}
+
+	generator, err := llmFromEnv(provider)
+	if err != nil {
+		return nil, err
+	}
+	return generator, nil
+}
+
+func llmFromEnv(provider string) (*llmRanker, error) {
+	switch provider {
+	case "", ProviderOpenAI:
+		return newOpenAI(
+			envOr("LLM_BASE_URL", defaultOpenAIBaseURL),
+			envOr("LLM_API_KEY", os.Getenv("GROQ_API_KEY")),
+			envOr("LLM_MODEL", defaultOpenAIModel),
+		)
+	case ProviderOllama:
+		return newOllama(
+			envOr("OLLAMA_SERVER_URL", defaultOllamaURL),
+			envOr("OLLAMA_MODEL", defaultOllamaModel),
+		)
+	}
+	return nil, fmt.Errorf("unknown REVIEW_RANKER %q", provider)
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+type llmRanker struct {
+	llm   llms.Model
+	model string
+}
+
+// NewOpenAI returns a ranker for any OpenAI compatible chat endpoint.
+func NewOpenAI(baseURL, token, model string) (ReviewRanker, error) {
+	return newOpenAI(baseURL, token, model)
+}
+
+// NewOllama returns a ranker for a local Ollama server.
+func NewOllama(serverURL, model string) (ReviewRanker, error) {
+	return newOllama(serverURL, model)
+}
+
+func newOpenAI(baseURL, token, model string) (*llmRanker, error) {
+	if token == "" {
+		return nil, errors.New("no API key configured for the OpenAI compatible ranker")
+	}
+
+	llm, err := openai.New(
+		openai.WithToken(token),
+		openai.WithBaseURL(baseURL),
+		openai.WithModel(model),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &llmRanker{llm: llm, model: ProviderOpenAI + "/" + model}, nil
+}
+
+func newOllama(serverURL, model string) (*llmRanker, error) {
+	llm, err := ollama.New(
+		ollama.WithServerURL(serverURL),
+		ollama.WithModel(model),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &llmRanker{llm: llm, model: ProviderOllama + "/" + model}, nil
+}
+
+func (r *llmRanker) Model() string {
+	return r.model
+}
+
+func (r *llmRanker) Rank(ctx context.Context, req RankRequest) (RankResult, error) {
+	return r.Generate(ctx, req.Prompt)
+}
+
+func (r *llmRanker) Generate(ctx context.Context, prompt string) (RankResult, error) {
+	response, err := llms.GenerateFromSinglePrompt(ctx, r.llm, prompt)
+	if err != nil {
+		return RankResult{}, err
+	}
+	return RankResult{Response: response, Model: r.model}, nil
+}
+
+type fallbackRanker struct {
+	primary  ReviewRanker
+	fallback ReviewRanker
+}
+
+// WithFallback ranks with primary and retries with fallback when primary
+// returns an error.
+func WithFallback(primary, fallback ReviewRanker) ReviewRanker {
+	return &fallbackRanker{primary: primary, fallback: fallback}
+}
+
+func (r *fallbackRanker) Model() string {
+	return r.primary.Model()
+}
+
+func (r *fallbackRanker) Rank(ctx context.Context, req RankRequest) (RankResult, error) {
+	result, err := r.primary.Rank(ctx, req)
+	if err == nil {
+		return result, nil
+	}
+	return r.fallback.Rank(ctx, req)
+}
@@ -0,0 +1,69 @@
+package recommend
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Variant is one arm of an experiment: a strategy and its share of users.
+type Variant struct {
+	Strategy string `json:"strategy"`
+	Weight   int    `json:"weight"`
+}
+
+// Experiment splits users between strategies. Assignment hashes the
+// experiment name with the user id, so a user stays in the same variant
+// for the life of an experiment and lands independently in the next one.
+type Experiment struct {
+	Name     string    `json:"name"`
+	Variants []Variant `json:"variants"`
+}
+
+// ParseExperiment reads an experiment written as
+// "name:strategy=weight,strategy=weight", e.g. "cf-v1:genre=50,collaborative=50".
+func ParseExperiment(spec string) (*Experiment, error) {
+	name, arms, ok := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("experiment %q must look like name:strategy=weight,...", spec)
+	}
+
+	experiment := &Experiment{Name: name}
+	seen := map[string]bool{}
+	for _, arm := range strings.Split(arms, ",") {
+		strategy, weightStr, ok := strings.Cut(arm, "=")
+		strategy = strings.TrimSpace(strategy)
+		weight, err := strconv.Atoi(strings.TrimSpace(weightStr))
+		if !ok || strategy == "" || err != nil || weight < 1 {
+			return nil, fmt.Errorf("experiment %s: variant %q must be strategy=positive weight", name, arm)
+		}
+		if seen[strategy] {
+			return nil, fmt.Errorf("experiment %s lists %s twice", name, strategy)
+		}
+		seen[strategy] = true
+		experiment.Variants = append(experiment.Variants, Variant{Strategy: strategy, Weight: weight})
+	}
+	return experiment, nil
+}
+
+// Assign returns the strategy the user is bucketed into.
+func (e *Experiment) Assign(userID string) string {
+	total := 0
+	for _, variant := range e.Variants {
+		total += variant.Weight
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(e.Name + "\x00" + userID))
+	bucket := int(h.Sum32() % uint32(total))
+
+	for _, variant := range e.Variants {
+		if bucket < variant.Weight {
+			return variant.Strategy
+		}
+		bucket -= variant.Weight
+	}
+	return e.Variants[len(e.Variants)-1].Strategy
+}
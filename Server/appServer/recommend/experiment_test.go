@@ -0,0 +1,77 @@
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestParseExperiment(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []Variant
+		wantErr bool
+	}{
+		{spec: "cf-v1:genre=50,collaborative=50", want: []Variant{{"genre", 50}, {"collaborative", 50}}},
+		{spec: " cf-v1 : genre = 1 , collaborative = 3 ", want: []Variant{{"genre", 1}, {"collaborative", 3}}},
+		{spec: "genre=50", wantErr: true},
+		{spec: ":genre=50", wantErr: true},
+		{spec: "cf:genre=0", wantErr: true},
+		{spec: "cf:genre=abc", wantErr: true},
+		{spec: "cf:genre", wantErr: true},
+		{spec: "cf:genre=1,genre=2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		experiment, err := ParseExperiment(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseExperiment(%q) returned no error", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseExperiment(%q) error: %v", tt.spec, err)
+			continue
+		}
+		if !slices.Equal(experiment.Variants, tt.want) {
+			t.Errorf("ParseExperiment(%q) variants = %v, want %v", tt.spec, experiment.Variants, tt.want)
+		}
+	}
+}
+
+func TestAssign(t *testing.T) {
+	tests := []struct {
+		spec string
+		want map[string]float64
+	}{
+		{spec: "solo:genre=1", want: map[string]float64{"genre": 1}},
+		{spec: "even:genre=50,collaborative=50", want: map[string]float64{"genre": 0.5, "collaborative": 0.5}},
+		{spec: "skewed:genre=1,collaborative=3", want: map[string]float64{"genre": 0.25, "collaborative": 0.75}},
+	}
+
+	const users = 10000
+	for _, tt := range tests {
+		experiment, err := ParseExperiment(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseExperiment(%q) error: %v", tt.spec, err)
+		}
+
+		counts := map[string]int{}
+		for i := range users {
+			userID := fmt.Sprintf("user-%d", i)
+			strategy := experiment.Assign(userID)
+			if again := experiment.Assign(userID); again != strategy {
+				t.Fatalf("%s: Assign(%q) = %q then %q", tt.spec, userID, strategy, again)
+			}
+			counts[strategy]++
+		}
+
+		for strategy, share := range tt.want {
+			if got := float64(counts[strategy]) / users; math.Abs(got-share) > 0.03 {
+				t.Errorf("%s: %s got %.3f of users, want about %.2f", tt.spec, strategy, got, share)
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/catalog"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/popularity"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// a movie counts as trending in an explanation when it is this high in
+// the trending feed for this window
+const (
+	trendingReasonWindow = "7d"
+	trendingReasonTop    = 20
+)
+
+// Explain gives the reasons each movie suits the user. Reasons are worked
+// out from the movie and the user rather than taken from the strategy,
+// so every strategy's recommendations are explained the same way.
+func (s *Service) Explain(ctx context.Context, req Request, movies []models.Movie) ([]models.RecommendedMovie, error) {
+	similar, err := s.similarWatched(ctx, req.UserID, movies)
+	if err != nil {
+		return nil, err
+	}
+
+	highlyRanked, err := s.highlyRanked(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trending, err := popularity.TrendingIDs(ctx, s.client, trendingReasonWindow, trendingReasonTop)
+	if err != nil {
+		return nil, err
+	}
+
+	favourite := map[string]bool{}
+	for _, genre := range req.FavouriteGenres {
+		favourite[genre] = true
+	}
+
+	explained := make([]models.RecommendedMovie, len(movies))
+	for i, movie := range movies {
+		reasons := []models.RecommendationReason{}
+		if reason, ok := similar[movie.ImdbID]; ok {
+			reasons = append(reasons, reason)
+		}
+		for _, genre := range movie.Genre {
+			if favourite[genre.GenreName] {
+				reasons = append(reasons, models.RecommendationReason{Type: models.ReasonFavouriteGenre, Genre: genre.GenreName})
+				break
+			}
+		}
+		if !movie.RankingPending && highlyRanked[movie.Ranking.RankingValue] {
+			reasons = append(reasons, models.RecommendationReason{Type: models.ReasonHighlyRanked, Ranking: movie.Ranking.RankingName})
+		}
+		if trending[movie.ImdbID] {
+			reasons = append(reasons, models.RecommendationReason{Type: models.ReasonTrending, Window: trendingReasonWindow})
+		}
+
+		explained[i] = models.RecommendedMovie{Movie: movie, Reasons: reasons, Explanation: explanation(reasons)}
+	}
+	return explained, nil
+}
+
+// similarWatched finds, for each movie, the title the user liked that
+// contributes most to its similarity.
+func (s *Service) similarWatched(ctx context.Context, userID string, movies []models.Movie) (map[string]models.RecommendationReason, error) {
+	signals, err := UserSignals(ctx, s.client, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(movies))
+	for i, movie := range movies {
+		ids[i] = movie.ImdbID
+	}
+	neighbours, err := Neighbours(ctx, s.client, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	because := map[string]string{}
+	var liked []string
+	for imdbID, list := range neighbours {
+		best := 0.0
+		for _, neighbour := range list {
+			if contribution := signals[neighbour.ImdbID] * neighbour.Score; contribution > best {
+				best = contribution
+				because[imdbID] = neighbour.ImdbID
+			}
+		}
+		if best > 0 {
+			liked = append(liked, because[imdbID])
+		}
+	}
+
+	reasons := map[string]models.RecommendationReason{}
+	if len(liked) == 0 {
+		return reasons, nil
+	}
+
+	cursor, err := database.OpenCollection("movies", s.client).Find(ctx,
+		bson.M{"imdb_id": bson.M{"$in": liked}, "deleted_at": nil},
+		options.Find().SetProjection(bson.M{"imdb_id": 1, "title": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var found []models.Movie
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	titles := map[string]string{}
+	for _, movie := range found {
+		titles[movie.ImdbID] = movie.Title
+	}
+
+	for imdbID, likedID := range because {
+		if title, ok := titles[likedID]; ok {
+			reasons[imdbID] = models.RecommendationReason{Type: models.ReasonSimilarToWatched, ImdbID: likedID, Title: title}
+		}
+	}
+	return reasons, nil
+}
+
+// highlyRanked returns the ranking values in the better half of the
+// active scale, at least the best level.
+func (s *Service) highlyRanked(ctx context.Context) (map[int]bool, error) {
+	rankings, err := catalog.ListRankings(ctx, s.client, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranked []models.Ranking
+	for _, ranking := range rankings {
+		if !ranking.Unranked {
+			ranked = append(ranked, ranking)
+		}
+	}
+
+	values := map[int]bool{}
+	for _, ranking := range ranked[:min(len(ranked), max(1, len(ranked)/2))] {
+		values[ranking.RankingValue] = true
+	}
+	return values, nil
+}
+
+func explanation(reasons []models.RecommendationReason) string {
+	if len(reasons) == 0 {
+		return "Recommended for you."
+	}
+
+	sentences := make([]string, len(reasons))
+	for i, reason := range reasons {
+		switch reason.Type {
+		case models.ReasonSimilarToWatched:
+			sentences[i] = fmt.Sprintf("Similar to %s.", reason.Title)
+		case models.ReasonFavouriteGenre:
+			sentences[i] = fmt.Sprintf("%s is one of your favourite genres.", reason.Genre)
+		case models.ReasonHighlyRanked:
+			sentences[i] = fmt.Sprintf("Ranked %s by our reviewers.", reason.Ranking)
+		case models.ReasonTrending:
+			sentences[i] = "Trending this week."
+		}
+	}
+	return strings.Join(sentences, " ")
+}
@@ -0,0 +1,209 @@
+package recommend
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// users with this many positive signals get purely collaborative
+// recommendations; below it the genre filter makes up the difference
+const coldStartSignals = 5
+
+type candidate struct {
+	imdbID    string
+	score     float64
+	genreRank int
+}
+
+// Collaborative recommends movies the user has not interacted with yet.
+// Movies are scored by their similarity to what the user liked, blended
+// with the favourite genre filter in proportion to how little is known
+// about the user, so a new user sees the genre results and the genre
+// results fill any gap the collaborative scores leave.
+type Collaborative struct {
+	client *mongo.Client
+}
+
+func NewCollaborative(client *mongo.Client) *Collaborative {
+	return &Collaborative{client: client}
+}
+
+func (r *Collaborative) Name() string {
+	return "collaborative"
+}
+
+func (r *Collaborative) Recommend(ctx context.Context, req Request) ([]models.Movie, error) {
+	if req.Limit < 1 {
+		return []models.Movie{}, nil
+	}
+
+	signals, err := UserSignals(ctx, r.client, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	collaborative, err := collaborativeScores(ctx, r.client, signals)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make([]string, 0, len(signals))
+	positive := 0
+	for imdbID, weight := range signals {
+		seen = append(seen, imdbID)
+		if weight > 0 {
+			positive++
+		}
+	}
+	genre, err := genreCandidates(ctx, r.client, req.FavouriteGenres, seen, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return rankCandidates(ctx, r.client, blend(collaborative, genre, positive), req.Limit)
+}
+
+// collaborativeScores sums, for every neighbour of a movie the user has a
+// signal for, the signal weighted by the similarity. Movies the user
+// already has a signal for are left out.
+func collaborativeScores(ctx context.Context, client *mongo.Client, signals Signals) (map[string]float64, error) {
+	ids := make([]string, 0, len(signals))
+	for imdbID := range signals {
+		ids = append(ids, imdbID)
+	}
+	neighbours, err := Neighbours(ctx, client, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := map[string]float64{}
+	for imdbID, weight := range signals {
+		for _, neighbour := range neighbours[imdbID] {
+			if _, seen := signals[neighbour.ImdbID]; !seen {
+				scores[neighbour.ImdbID] += weight * neighbour.Score
+			}
+		}
+	}
+	for imdbID, score := range scores {
+		if score <= 0 {
+			delete(scores, imdbID)
+		}
+	}
+	return scores, nil
+}
+
+// genreCandidates is the original recommendation: movies in the user's
+// favourite genres, best ranked first. It fetches a few pages' worth so
+// the blend has something to fill with.
+func genreCandidates(ctx context.Context, client *mongo.Client, favouriteGenres, seen []string, limit int64) ([]string, error) {
+	if len(favouriteGenres) == 0 {
+		return nil, nil
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "ranking.ranking_value", Value: 1}}).
+		SetLimit(limit * 4).
+		SetProjection(bson.M{"imdb_id": 1})
+	cursor, err := database.OpenCollection("movies", client).Find(ctx, bson.M{
+		"genre.genre_name": bson.M{"$in": favouriteGenres},
+		"imdb_id":          bson.M{"$nin": seen},
+		"deleted_at":       nil,
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var movies []models.Movie
+	if err := cursor.All(ctx, &movies); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(movies))
+	for i, movie := range movies {
+		ids[i] = movie.ImdbID
+	}
+	return ids, nil
+}
+
+// blend weights the normalised collaborative scores by how much is known
+// about the user and the genre positions by the rest. Ties, including
+// genre movies that carry no weight at all, keep genre order.
+func blend(collaborative map[string]float64, genre []string, positiveSignals int) []candidate {
+	confidence := min(1, float64(positiveSignals)/coldStartSignals)
+
+	best := 0.0
+	for _, score := range collaborative {
+		best = max(best, score)
+	}
+
+	byID := map[string]*candidate{}
+	for imdbID, score := range collaborative {
+		byID[imdbID] = &candidate{imdbID: imdbID, score: confidence * score / best, genreRank: len(genre)}
+	}
+	for rank, imdbID := range genre {
+		c := byID[imdbID]
+		if c == nil {
+			c = &candidate{imdbID: imdbID}
+			byID[imdbID] = c
+		}
+		c.genreRank = rank
+		c.score += (1 - confidence) * (1 - float64(rank)/float64(len(genre)))
+	}
+
+	candidates := make([]candidate, 0, len(byID))
+	for _, c := range byID {
+		candidates = append(candidates, *c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.genreRank != b.genreRank {
+			return a.genreRank < b.genreRank
+		}
+		return a.imdbID < b.imdbID
+	})
+	return candidates
+}
+
+// rankCandidates loads the best candidates in order, skipping deleted
+// movies, until it has limit of them.
+func rankCandidates(ctx context.Context, client *mongo.Client, candidates []candidate, limit int64) ([]models.Movie, error) {
+	movies := []models.Movie{}
+	for start := 0; start < len(candidates) && int64(len(movies)) < limit; start += int(limit) {
+		end := min(start+int(limit), len(candidates))
+		ids := make([]string, 0, end-start)
+		for _, c := range candidates[start:end] {
+			ids = append(ids, c.imdbID)
+		}
+
+		cursor, err := database.OpenCollection("movies", client).Find(ctx,
+			bson.M{"imdb_id": bson.M{"$in": ids}, "deleted_at": nil},
+			options.Find().SetProjection(bson.M{"embedding": 0}),
+		)
+		if err != nil {
+			return nil, err
+		}
+		var found []models.Movie
+		if err := cursor.All(ctx, &found); err != nil {
+			return nil, err
+		}
+		byID := map[string]models.Movie{}
+		for _, movie := range found {
+			byID[movie.ImdbID] = movie
+		}
+
+		for _, id := range ids {
+			if movie, ok := byID[id]; ok && int64(len(movies)) < limit {
+				movies = append(movies, movie)
+			}
+		}
+	}
+	return movies, nil
+}
@@ -0,0 +1,55 @@
+package recommend
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBlend(t *testing.T) {
+	tests := []struct {
+		name            string
+		collaborative   map[string]float64
+		genre           []string
+		positiveSignals int
+		want            []string
+	}{
+		{
+			name:  "new user gets genre order",
+			genre: []string{"tt3", "tt1", "tt2"},
+			want:  []string{"tt3", "tt1", "tt2"},
+		},
+		{
+			name:            "collaborative scores ignored without signals",
+			collaborative:   map[string]float64{"tt9": 4, "tt8": 2},
+			genre:           []string{"tt1", "tt2"},
+			positiveSignals: 0,
+			want:            []string{"tt1", "tt2", "tt8", "tt9"},
+		},
+		{
+			name:            "enough signals is purely collaborative",
+			collaborative:   map[string]float64{"tt9": 4, "tt8": 2},
+			genre:           []string{"tt1", "tt2"},
+			positiveSignals: coldStartSignals,
+			want:            []string{"tt9", "tt8", "tt1", "tt2"},
+		},
+		{
+			name:            "movies in both lists add up",
+			collaborative:   map[string]float64{"tt2": 1, "tt9": 1},
+			genre:           []string{"tt1", "tt2"},
+			positiveSignals: 2,
+			want:            []string{"tt2", "tt1", "tt9"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range blend(tt.collaborative, tt.genre, tt.positiveSignals) {
+				got = append(got, c.imdbID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("blend order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package recommend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var ErrUnknownStrategy = errors.New("unknown recommendation strategy")
+
+// Request is what a strategy knows about the user it recommends for.
+type Request struct {
+	UserID          string
+	FavouriteGenres []string
+	Limit           int64
+}
+
+// Recommender is one recommendation strategy. Name identifies it in the
+// registry, experiment definitions and exposure logs.
+type Recommender interface {
+	Name() string
+	Recommend(ctx context.Context, req Request) ([]models.Movie, error)
+}
+
+// Genre recommends the best ranked movies in the user's favourite genres,
+// the same for every user with the same genres.
+type Genre struct {
+	client *mongo.Client
+}
+
+func NewGenre(client *mongo.Client) *Genre {
+	return &Genre{client: client}
+}
+
+func (r *Genre) Name() string {
+	return "genre"
+}
+
+func (r *Genre) Recommend(ctx context.Context, req Request) ([]models.Movie, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "ranking.ranking_value", Value: 1}}).
+		SetLimit(req.Limit).
+		SetProjection(bson.M{"embedding": 0})
+	filter := bson.M{"genre.genre_name": bson.M{"$in": req.FavouriteGenres}, "deleted_at": nil}
+
+	cursor, err := database.OpenCollection("movies", r.client).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	movies := []models.Movie{}
+	if err := cursor.All(ctx, &movies); err != nil {
+		return nil, err
+	}
+	return movies, nil
+}
+
+// Registry holds the strategies recommendations can be served by.
+type Registry struct {
+	strategies map[string]Recommender
+}
+
+// NewRegistry returns a registry holding the built-in strategies.
+func NewRegistry(client *mongo.Client) *Registry {
+	registry := &Registry{strategies: map[string]Recommender{}}
+	registry.Register(NewGenre(client))
+	registry.Register(NewCollaborative(client))
+	return registry
+}
+
+// Register adds a strategy, replacing any registered under the same name.
+func (r *Registry) Register(strategy Recommender) {
+	r.strategies[strategy.Name()] = strategy
+}
+
+func (r *Registry) Get(name string) (Recommender, error) {
+	strategy, ok := r.strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStrategy, name)
+	}
+	return strategy, nil
+}
+
+// Names lists the registered strategies in name order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.strategies))
+	for name := range r.strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
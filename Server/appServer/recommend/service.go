@@ -0,0 +1,136 @@
+package recommend
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const defaultStrategy = "collaborative"
+
+// Service picks the strategy for each user, serves their recommendations
+// and logs what was shown.
+type Service struct {
+	client     *mongo.Client
+	registry   *Registry
+	strategy   string
+	experiment *Experiment
+}
+
+// Result is a served list of recommendations and what produced it.
+type Result struct {
+	Movies     []models.Movie
+	Strategy   string
+	Experiment string
+	ExposureID string
+}
+
+// Config describes how a Service assigns strategies.
+type Config struct {
+	Strategies      []string    `json:"strategies"`
+	DefaultStrategy string      `json:"default_strategy"`
+	Experiment      *Experiment `json:"experiment,omitempty"`
+}
+
+// NewService serves strategy to everyone, or, with an experiment, splits
+// users between the experiment's strategies. Every strategy named must be
+// registered.
+func NewService(client *mongo.Client, registry *Registry, strategy string, experiment *Experiment) (*Service, error) {
+	if _, err := registry.Get(strategy); err != nil {
+		return nil, err
+	}
+	if experiment != nil {
+		for _, variant := range experiment.Variants {
+			if _, err := registry.Get(variant.Strategy); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &Service{client: client, registry: registry, strategy: strategy, experiment: experiment}, nil
+}
+
+// ServiceFromEnv serves RECOMMENDER_STRATEGY, collaborative by default,
+// unless RECOMMENDER_EXPERIMENT defines an experiment. An invalid
+// experiment is logged and ignored, and an unknown strategy falls back to
+// the default.
+func ServiceFromEnv(client *mongo.Client) *Service {
+	registry := NewRegistry(client)
+
+	strategy := os.Getenv("RECOMMENDER_STRATEGY")
+	if strategy == "" {
+		strategy = defaultStrategy
+	}
+	if _, err := registry.Get(strategy); err != nil {
+		log.Printf("Falling back to %s recommendations: %v", defaultStrategy, err)
+		strategy = defaultStrategy
+	}
+
+	var experiment *Experiment
+	if spec := os.Getenv("RECOMMENDER_EXPERIMENT"); spec != "" {
+		parsed, err := ParseExperiment(spec)
+		if err != nil {
+			log.Println("Ignoring RECOMMENDER_EXPERIMENT:", err)
+		} else {
+			experiment = parsed
+		}
+	}
+
+	service, err := NewService(client, registry, strategy, experiment)
+	if err != nil {
+		log.Println("Ignoring RECOMMENDER_EXPERIMENT:", err)
+		service, _ = NewService(client, registry, strategy, nil)
+	}
+	return service
+}
+
+func (s *Service) Config() Config {
+	return Config{
+		Strategies:      s.registry.Names(),
+		DefaultStrategy: s.strategy,
+		Experiment:      s.experiment,
+	}
+}
+
+// Recommend serves the user's recommendations from their assigned
+// strategy. Logging the exposure is best effort: a failure is logged and
+// the recommendations are still served, without an exposure id.
+func (s *Service) Recommend(ctx context.Context, req Request) (Result, error) {
+	result := Result{Strategy: s.strategy}
+	if s.experiment != nil {
+		result.Experiment = s.experiment.Name
+		result.Strategy = s.experiment.Assign(req.UserID)
+	}
+
+	strategy, err := s.registry.Get(result.Strategy)
+	if err != nil {
+		return result, err
+	}
+	result.Movies, err = strategy.Recommend(ctx, req)
+	if err != nil {
+		return result, err
+	}
+
+	exposure := models.RecommendationExposure{
+		ID:         bson.NewObjectID(),
+		UserID:     req.UserID,
+		Experiment: result.Experiment,
+		Strategy:   result.Strategy,
+		ImdbIDs:    make([]string, len(result.Movies)),
+		ServedAt:   time.Now(),
+	}
+	for i, movie := range result.Movies {
+		exposure.ImdbIDs[i] = movie.ImdbID
+	}
+	if _, err := database.OpenCollection("recommendation_exposures", s.client).InsertOne(ctx, exposure); err != nil {
+		log.Println("Error logging recommendation exposure:", err)
+	} else {
+		result.ExposureID = exposure.ID.Hex()
+	}
+	return result, nil
+}
@@ -0,0 +1,167 @@
+package recommend
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// how strongly each kind of interaction says a user liked a movie
+const (
+	watchlistWeight  = 0.5
+	startedWeight    = 0.2
+	completedWeight  = 1.0
+	maxSignalsByUser = 300
+)
+
+// ratingWeights maps star ratings onto signal weights. Low ratings are
+// negative: they never build similarity but push a user's
+// recommendations away from the movie's neighbours.
+var ratingWeights = map[int]float64{1: -1, 2: -0.5, 3: 0.3, 4: 0.8, 5: 1}
+
+// Signals holds, per movie, how much a user liked it. Each movie keeps its
+// strongest signal, except that a rating always wins, since it is the only
+// one the user gave on purpose.
+type Signals map[string]float64
+
+type signal struct {
+	weight float64
+	rated  bool
+}
+
+type signalSet map[string]signal
+
+func (s signalSet) add(imdbID string, weight float64, rated bool) {
+	current, ok := s[imdbID]
+	switch {
+	case !ok:
+	case rated && !current.rated:
+	case current.rated && !rated:
+		return
+	case weight <= current.weight:
+		return
+	}
+	s[imdbID] = signal{weight: weight, rated: rated}
+}
+
+// signals keeps the maxSignalsByUser strongest, so one heavy user cannot
+// dominate the similarity job's pair counts.
+func (s signalSet) signals() Signals {
+	ids := make([]string, 0, len(s))
+	for id := range s {
+		ids = append(ids, id)
+	}
+	if len(ids) > maxSignalsByUser {
+		sort.Slice(ids, func(i, j int) bool { return math.Abs(s[ids[i]].weight) > math.Abs(s[ids[j]].weight) })
+		ids = ids[:maxSignalsByUser]
+	}
+
+	signals := make(Signals, len(ids))
+	for _, id := range ids {
+		signals[id] = s[id].weight
+	}
+	return signals
+}
+
+func historyWeight(entry models.WatchHistoryEntry) float64 {
+	if entry.Completed || entry.TimesCompleted > 0 {
+		return completedWeight
+	}
+	return max(startedWeight, entry.Progress*0.6)
+}
+
+// UserSignals gathers one user's ratings, watchlist and watch history.
+func UserSignals(ctx context.Context, client *mongo.Client, userID string) (Signals, error) {
+	all, err := loadSignals(ctx, client, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	return all[userID], nil
+}
+
+// AllSignals gathers every user's signals, keyed by user id.
+func AllSignals(ctx context.Context, client *mongo.Client) (map[string]Signals, error) {
+	return loadSignals(ctx, client, bson.M{})
+}
+
+func loadSignals(ctx context.Context, client *mongo.Client, filter bson.M) (map[string]Signals, error) {
+	sets := map[string]signalSet{}
+	set := func(userID string) signalSet {
+		if sets[userID] == nil {
+			sets[userID] = signalSet{}
+		}
+		return sets[userID]
+	}
+
+	// hidden and rejected reviews still tell us what the user thought
+	cursor, err := database.OpenCollection("user_reviews", client).Find(ctx, filter,
+		options.Find().SetProjection(bson.M{"user_id": 1, "imdb_id": 1, "rating": 1}))
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var review models.UserReview
+		if err := cursor.Decode(&review); err != nil {
+			cursor.Close(ctx)
+			return nil, err
+		}
+		if weight, ok := ratingWeights[review.Rating]; ok {
+			set(review.UserID).add(review.ImdbID, weight, true)
+		}
+	}
+	if err := closeCursor(ctx, cursor); err != nil {
+		return nil, err
+	}
+
+	cursor, err = database.OpenCollection("watchlists", client).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var watchlist models.Watchlist
+		if err := cursor.Decode(&watchlist); err != nil {
+			cursor.Close(ctx)
+			return nil, err
+		}
+		for _, item := range watchlist.Items {
+			set(watchlist.UserID).add(item.ImdbID, watchlistWeight, false)
+		}
+	}
+	if err := closeCursor(ctx, cursor); err != nil {
+		return nil, err
+	}
+
+	cursor, err = database.OpenCollection("watch_history", client).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var entry models.WatchHistoryEntry
+		if err := cursor.Decode(&entry); err != nil {
+			cursor.Close(ctx)
+			return nil, err
+		}
+		set(entry.UserID).add(entry.ImdbID, historyWeight(entry), false)
+	}
+	if err := closeCursor(ctx, cursor); err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]Signals, len(sets))
+	for userID, s := range sets {
+		all[userID] = s.signals()
+	}
+	return all, nil
+}
+
+func closeCursor(ctx context.Context, cursor *mongo.Cursor) error {
+	err := cursor.Err()
+	cursor.Close(ctx)
+	return err
+}
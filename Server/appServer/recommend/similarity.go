@@ -0,0 +1,172 @@
+package recommend
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	// neighbours kept per movie
+	maxNeighbours = 30
+	// pairs seen together by fewer users are treated as noise
+	minSupport = 2
+	// damps the similarity of pairs with little support: a pair seen by n
+	// users keeps n/(n+shrinkage) of its cosine
+	shrinkage      = 5.0
+	writeBatchSize = 500
+)
+
+// BuildSimilarities recomputes the item-item similarity of every movie
+// from all users' positive signals and replaces the stored neighbour
+// lists. It returns how many movies have neighbours.
+func BuildSimilarities(ctx context.Context, client *mongo.Client) (int, error) {
+	started := time.Now()
+
+	all, err := AllSignals(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+
+	similarities := itemSimilarities(all)
+
+	collection := database.OpenCollection("movie_similarities", client)
+	var writes []mongo.WriteModel
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+		_, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		writes = writes[:0]
+		return err
+	}
+
+	now := time.Now()
+	for imdbID, neighbours := range similarities {
+		doc := models.MovieSimilarity{ImdbID: imdbID, Neighbours: neighbours, ComputedAt: now}
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": imdbID}).
+			SetReplacement(doc).
+			SetUpsert(true))
+		if len(writes) == writeBatchSize {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	// movies that lost all their neighbours since the last run
+	if _, err := collection.DeleteMany(ctx, bson.M{"computed_at": bson.M{"$lt": started}}); err != nil {
+		return len(similarities), err
+	}
+	return len(similarities), nil
+}
+
+// itemSimilarities scores every pair of movies liked by the same users
+// with the cosine of their signal vectors, and keeps each movie's
+// strongest neighbours.
+func itemSimilarities(all map[string]Signals) map[string][]models.SimilarMovie {
+	type pair struct {
+		dot     float64
+		support int
+	}
+
+	index := map[string]int{}
+	var ids []string
+	norms := []float64{}
+	pairs := map[[2]int]*pair{}
+
+	for _, signals := range all {
+		var liked []int
+		var weights []float64
+		for imdbID, weight := range signals {
+			if weight <= 0 {
+				continue
+			}
+			i, ok := index[imdbID]
+			if !ok {
+				i = len(ids)
+				index[imdbID] = i
+				ids = append(ids, imdbID)
+				norms = append(norms, 0)
+			}
+			norms[i] += weight * weight
+			liked = append(liked, i)
+			weights = append(weights, weight)
+		}
+
+		for a := range liked {
+			for b := a + 1; b < len(liked); b++ {
+				key := [2]int{liked[a], liked[b]}
+				if key[0] > key[1] {
+					key[0], key[1] = key[1], key[0]
+				}
+				p := pairs[key]
+				if p == nil {
+					p = &pair{}
+					pairs[key] = p
+				}
+				p.dot += weights[a] * weights[b]
+				p.support++
+			}
+		}
+	}
+
+	neighbours := map[string][]models.SimilarMovie{}
+	for key, p := range pairs {
+		if p.support < minSupport {
+			continue
+		}
+		score := p.dot / math.Sqrt(norms[key[0]]*norms[key[1]])
+		score *= float64(p.support) / (float64(p.support) + shrinkage)
+
+		a, b := ids[key[0]], ids[key[1]]
+		neighbours[a] = append(neighbours[a], models.SimilarMovie{ImdbID: b, Score: score, Support: p.support})
+		neighbours[b] = append(neighbours[b], models.SimilarMovie{ImdbID: a, Score: score, Support: p.support})
+	}
+
+	for imdbID, list := range neighbours {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			return list[i].ImdbID < list[j].ImdbID
+		})
+		if len(list) > maxNeighbours {
+			list = list[:maxNeighbours]
+		}
+		neighbours[imdbID] = list
+	}
+	return neighbours
+}
+
+// Neighbours loads the stored neighbour lists of the given movies.
+func Neighbours(ctx context.Context, client *mongo.Client, imdbIDs []string) (map[string][]models.SimilarMovie, error) {
+	byID := map[string][]models.SimilarMovie{}
+	if len(imdbIDs) == 0 {
+		return byID, nil
+	}
+
+	cursor, err := database.OpenCollection("movie_similarities", client).Find(ctx, bson.M{"_id": bson.M{"$in": imdbIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var docs []models.MovieSimilarity
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		byID[doc.ImdbID] = doc.Neighbours
+	}
+	return byID, nil
+}
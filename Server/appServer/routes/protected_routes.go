@@ -2,16 +2,78 @@ package routes
 
 import (
 	controller "github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/controllers"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/embedding"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/jobs"
 	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/middleware"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/recommend"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
-func SetupProtectedRoutes(router *gin.Engine, client *mongo.Client) {
+func SetupProtectedRoutes(router *gin.Engine, client *mongo.Client, rankingQueue *jobs.RankingQueue, reranker *jobs.Reranker, embedder embedding.Embedder, embeddingIndexer *jobs.EmbeddingIndexer, similarityBuilder *jobs.SimilarityBuilder, recommender *recommend.Service, popularityAggregator *jobs.PopularityAggregator) {
 	router.Use(middleware.AuthMiddleware())
 
 	router.GET("/movie/:imdb_id", controller.GetMovie(client))
+	router.GET("/movie/:imdb_id/similar", controller.GetSimilarMovies(client, embedder))
+	// every query is a paid embedding call, so it needs a signed in user
+	router.GET("/movies/semantic-search", controller.SemanticSearchMovies(client, embedder))
+	router.GET("/movie/:imdb_id/reviews", controller.GetMovieReviews(client))
+	router.GET("/movie/:imdb_id/reviews/me", controller.GetMyReview(client))
+	router.PUT("/movie/:imdb_id/reviews/me", controller.SaveMyReview(client))
+	router.DELETE("/movie/:imdb_id/reviews/me", controller.DeleteMyReview(client))
+	router.POST("/movie/:imdb_id/reviews/:review_id/report", controller.ReportReview(client))
 	router.POST("/addmovie", controller.AddMovie(client))
-	router.GET("/recommendedmovies", controller.GetRecommendedMovies(client))
-	router.PATCH("/updatereview/:imdb_id", controller.AdminReviewUpdate(client))
+	router.PUT("/movie/:imdb_id", controller.UpdateMovie(client))
+	router.PATCH("/movie/:imdb_id", controller.PatchMovie(client))
+	router.DELETE("/movie/:imdb_id", controller.DeleteMovie(client))
+	router.GET("/watchlist", controller.GetWatchlist(client))
+	router.POST("/watchlist", controller.AddToWatchlist(client))
+	router.PUT("/watchlist/order", controller.ReorderWatchlist(client))
+	router.DELETE("/watchlist/:imdb_id", controller.RemoveFromWatchlist(client))
+	router.GET("/watch-history", controller.GetWatchHistory(client))
+	router.DELETE("/watch-history", controller.ClearWatchHistory(client))
+	router.GET("/watch-history/continue", controller.GetContinueWatching(client))
+	router.GET("/watch-history/:imdb_id", controller.GetPlaybackPosition(client))
+	router.POST("/watch-history/:imdb_id/progress", controller.ReportPlayback(client))
+	router.DELETE("/watch-history/:imdb_id", controller.DeleteWatchHistoryEntry(client))
+	router.GET("/recommendedmovies", controller.GetRecommendedMovies(client, recommender))
+	router.PATCH("/updatereview/:imdb_id", controller.AdminReviewUpdate(client, rankingQueue))
+	router.GET("/movie/:imdb_id/ranking-history", controller.GetRankingHistory(client))
+	router.PUT("/movie/:imdb_id/ranking", controller.OverrideMovieRanking(client))
+	router.PATCH("/movie/:imdb_id/ranking/lock", controller.SetMovieRankingLock(client))
+	router.GET("/ranking-jobs/:job_id", controller.GetRankingJob(rankingQueue))
+	router.GET("/ranking-jobs/:job_id/events", controller.StreamRankingJob(rankingQueue))
+	router.POST("/admin/rerank", controller.StartRerank(reranker))
+	router.GET("/admin/rerank/:batch_id", controller.GetRerankBatch(reranker))
+	router.GET("/admin/rerank/:batch_id/results", controller.GetRerankResults(reranker))
+	router.POST("/admin/rerank/:batch_id/resume", controller.ResumeRerank(reranker))
+	router.POST("/admin/rerank/:batch_id/cancel", controller.CancelRerank(reranker))
+	router.POST("/admin/movies/import", controller.ImportMovies(client))
+	router.GET("/admin/export", controller.ExportCatalog(client))
+	router.GET("/admin/movies/duplicates", controller.GetDuplicateMovies(client))
+	router.GET("/admin/movies/:imdb_id/copy", controller.GetMovieCopy(client))
+	router.POST("/admin/movies/:imdb_id/copy/draft", controller.DraftMovieCopy(client))
+	router.POST("/admin/movies/:imdb_id/copy/approve", controller.ApproveMovieCopy(client))
+	router.DELETE("/admin/movies/:imdb_id/copy/draft", controller.DiscardMovieCopy(client))
+	router.GET("/admin/moderation/reviews", controller.GetModerationQueue(client))
+	router.POST("/admin/moderation/reviews/:review_id/approve", controller.ApproveReview(client))
+	router.POST("/admin/moderation/reviews/:review_id/reject", controller.RejectReview(client))
+	router.POST("/admin/embeddings/refresh", controller.RefreshEmbeddings(embeddingIndexer))
+	router.GET("/admin/recommendations/config", controller.GetRecommendationConfig(recommender))
+	router.POST("/admin/recommendations/refresh", controller.RefreshRecommendations(similarityBuilder))
+	router.POST("/admin/feeds/refresh", controller.RefreshMovieFeeds(popularityAggregator))
+	router.GET("/admin/prompts/:name", controller.GetPromptTemplate(client))
+	router.GET("/admin/prompts/:name/versions", controller.ListPromptTemplateVersions(client))
+	router.POST("/admin/prompts/:name", controller.SavePromptTemplate(client))
+	router.POST("/admin/prompts/:name/rollback", controller.RollbackPromptTemplate(client))
+	router.POST("/admin/prompts/:name/preview", controller.PreviewPromptTemplate(client))
+	router.DELETE("/admin/ranking-cache", controller.PurgeRankingCache(client))
+	router.POST("/genres", controller.CreateGenre(client))
+	router.PATCH("/genres/:genre_id", controller.RenameGenre(client))
+	router.DELETE("/genres/:genre_id", controller.RetireGenre(client))
+	router.GET("/rankings", controller.ListRankingLevels(client))
+	router.POST("/rankings", controller.CreateRankingLevel(client))
+	router.PUT("/rankings/order", controller.ReorderRankingLevels(client))
+	router.PATCH("/rankings/:ranking_value", controller.UpdateRankingLevel(client))
+	router.DELETE("/rankings/:ranking_value", controller.RetireRankingLevel(client))
 }
@@ -2,12 +2,18 @@ package routes
 
 import (
 	controller "github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/controllers"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/middleware"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 func SetupUnProtectedRoutes(router *gin.Engine, client *mongo.Client) {
-	router.GET("/movies", controller.GetMovies(client))
+	router.GET("/movies", middleware.OptionalAuthMiddleware(), controller.GetMovies(client))
+	router.GET("/movies/search", middleware.OptionalAuthMiddleware(), controller.SearchMovies(client))
+	router.GET("/movies/trending", middleware.OptionalAuthMiddleware(), controller.GetTrendingMovies(client))
+	router.GET("/movies/trending/:genre", middleware.OptionalAuthMiddleware(), controller.GetTrendingMovies(client))
+	router.GET("/movies/popular", middleware.OptionalAuthMiddleware(), controller.GetPopularMovies(client))
+	router.GET("/movies/popular/:genre", middleware.OptionalAuthMiddleware(), controller.GetPopularMovies(client))
 	router.POST("/register", controller.RegisterUser(client))
 	router.POST("/login", controller.LoginUser(client))
 	router.POST("/logout", controller.LogoutHandler(client))
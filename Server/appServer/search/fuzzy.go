@@ -0,0 +1,92 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize lowercases text and splits it into words.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// maxEdits is how many typos a query term of this length tolerates.
+func maxEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// termSimilarity scores how well a word matches a query term, from 0 (no
+// match) to 1 (exact match). Prefixes and words within the typo budget of
+// the term count as partial matches.
+func termSimilarity(term, word string) float64 {
+	if term == word {
+		return 1
+	}
+	if len(term) >= 3 && strings.HasPrefix(word, term) {
+		return 0.8
+	}
+
+	edits := maxEdits(term)
+	if edits == 0 {
+		return 0
+	}
+	distance := levenshtein(term, word)
+	if distance > edits {
+		return 0
+	}
+	return 0.7 - 0.2*float64(distance-1)
+}
+
+// bestSimilarity returns the best termSimilarity of term against words.
+func bestSimilarity(term string, words []string) float64 {
+	best := 0.0
+	for _, word := range words {
+		if score := termSimilarity(term, word); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// Similarity compares two strings by edit distance, from 0 (nothing in
+// common) to 1 (identical).
+func Similarity(a, b string) float64 {
+	longest := max(len([]rune(a)), len([]rune(b)))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,74 @@
+package search
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"matrix", "matrix", 0},
+		{"matrix", "matirx", 2},
+		{"kitten", "sitting", 3},
+		{"inception", "incepton", 1},
+		{"amélie", "amelie", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := levenshtein(tt.b, tt.a); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"good", "good", 1},
+		{"good", "", 0},
+		{"excelent", "excellent", 1 - 1.0/9},
+		{"bad", "good", 1 - 3.0/4},
+		{"abc", "xyz", 0},
+	}
+
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTermSimilarity(t *testing.T) {
+	tests := []struct {
+		term, word string
+		want       float64
+	}{
+		{"alien", "alien", 1},
+		{"ali", "aliens", 0.8},
+		{"al", "alien", 0},
+		{"cat", "bat", 0},
+		{"alein", "alien", 0},
+		{"aliem", "alien", 0.7},
+		{"interstelar", "interstellar", 0.7},
+		{"intrstelar", "interstellar", 0.5},
+		{"godfather", "alien", 0},
+	}
+
+	for _, tt := range tests {
+		if got := termSimilarity(tt.term, tt.word); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("termSimilarity(%q, %q) = %v, want %v", tt.term, tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const snippetWords = 30
+
+// Highlight wraps every word of text that matches one of the query terms in
+// <em> tags. Every word is HTML escaped, so the result is safe to render as
+// markup. When snippet is true only a window of words around the first
+// match is returned. ok is false when nothing in text matched.
+func Highlight(text string, terms []string, snippet bool) (highlighted string, ok bool) {
+	words := strings.Fields(text)
+	firstMatch := -1
+
+	for i, word := range words {
+		words[i] = html.EscapeString(word)
+		token := strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		}))
+		if token == "" {
+			continue
+		}
+		for _, term := range terms {
+			if termSimilarity(term, token) > 0 {
+				words[i] = "<em>" + words[i] + "</em>"
+				if firstMatch < 0 {
+					firstMatch = i
+				}
+				break
+			}
+		}
+	}
+
+	if firstMatch < 0 {
+		return "", false
+	}
+	if !snippet || len(words) <= snippetWords {
+		return strings.Join(words, " "), true
+	}
+
+	start := max(firstMatch-snippetWords/3, 0)
+	end := min(start+snippetWords, len(words))
+
+	highlighted = strings.Join(words[start:end], " ")
+	if start > 0 {
+		highlighted = "..." + highlighted
+	}
+	if end < len(words) {
+		highlighted = highlighted + "..."
+	}
+	return highlighted, true
+}
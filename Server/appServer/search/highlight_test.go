@@ -0,0 +1,52 @@
+package search
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		terms   []string
+		want    string
+		wantOK  bool
+		snippet bool
+	}{
+		{name: "exact word", text: "The Dark Knight", terms: []string{"dark"}, want: "The <em>Dark</em> Knight", wantOK: true},
+		{name: "typo", text: "The Dark Knight", terms: []string{"knigt"}, want: "The Dark <em>Knight</em>", wantOK: true},
+		{name: "no match", text: "The Dark Knight", terms: []string{"alien"}, wantOK: false},
+		{
+			name:   "markup is escaped",
+			text:   `<script>alert(1)</script> dark & "stormy"`,
+			terms:  []string{"dark"},
+			want:   `&lt;script&gt;alert(1)&lt;/script&gt; <em>dark</em> &amp; &#34;stormy&#34;`,
+			wantOK: true,
+		},
+		{
+			name:   "matched word is escaped",
+			text:   `"dark"`,
+			terms:  []string{"dark"},
+			want:   "<em>&#34;dark&#34;</em>",
+			wantOK: true,
+		},
+		{
+			name:    "snippet around the match",
+			text:    "one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twentyone twentytwo twentythree twentyfour twentyfive twentysix twentyseven twentyeight twentynine thirty thirtyone thirtytwo match thirtyfour",
+			terms:   []string{"match"},
+			snippet: true,
+			want:    "...twentythree twentyfour twentyfive twentysix twentyseven twentyeight twentynine thirty thirtyone thirtytwo <em>match</em> thirtyfour",
+			wantOK:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Highlight(tt.text, tt.terms, tt.snippet)
+			if ok != tt.wantOK {
+				t.Fatalf("Highlight(%q) ok = %v, want %v", tt.text, ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("Highlight(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package search
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/database"
+	"github.com/GavinLonDigital/MagicStream/Server/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// minimum average term similarity for a typo-tolerant match
+const fuzzyThreshold = 0.35
+
+// most movies the fuzzy fallback compares against the query
+const fuzzyScanLimit = 2000
+
+// Searcher finds movies matching a free-text query, best match first.
+type Searcher interface {
+	Search(ctx context.Context, query string, limit int64) ([]models.MovieSearchResult, error)
+}
+
+type mongoSearcher struct {
+	client *mongo.Client
+}
+
+// NewMongoSearcher returns a Searcher backed by the movies text index.
+// Terms the text index cannot match (typos, partial words) fall back to a
+// fuzzy comparison against movie titles and genre names. Text matches come
+// first, ordered by text score, followed by fuzzy matches ordered by their
+// 0-1 similarity; each result's Match says which score it carries.
+func NewMongoSearcher(client *mongo.Client) Searcher {
+	return &mongoSearcher{client: client}
+}
+
+type scoredMovie struct {
+	models.Movie `bson:",inline"`
+	Score        float64 `bson:"score"`
+	Match        string  `bson:"-"`
+}
+
+func (s *mongoSearcher) Search(ctx context.Context, query string, limit int64) ([]models.MovieSearchResult, error) {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return []models.MovieSearchResult{}, nil
+	}
+
+	movieCollection := database.OpenCollection("movies", s.client)
+
+	textOptions := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}, "embedding": 0}).
+		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}}).
+		SetLimit(limit)
+
+	cursor, err := movieCollection.Find(ctx, bson.M{"$text": bson.M{"$search": query}, "deleted_at": nil}, textOptions)
+	if err != nil {
+		return nil, err
+	}
+	var hits []scoredMovie
+	if err := cursor.All(ctx, &hits); err != nil {
+		return nil, err
+	}
+	for i := range hits {
+		hits[i].Match = models.SearchMatchText
+	}
+
+	if int64(len(hits)) < limit {
+		fuzzyHits, err := s.fuzzySearch(ctx, movieCollection, terms, hits, limit-int64(len(hits)))
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, fuzzyHits...)
+	}
+
+	results := make([]models.MovieSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, models.MovieSearchResult{
+			Movie:      hit.Movie,
+			Score:      hit.Score,
+			Match:      hit.Match,
+			Highlights: highlights(hit.Movie, terms),
+		})
+	}
+	return results, nil
+}
+
+// fuzzySearch scores the titles and genre lists of movies that share a
+// word initial with the query terms, at most fuzzyScanLimit of them, and
+// returns up to limit movies not already in found.
+func (s *mongoSearcher) fuzzySearch(ctx context.Context, movieCollection *mongo.Collection, terms []string, found []scoredMovie, limit int64) ([]scoredMovie, error) {
+	seen := make(map[string]bool, len(found))
+	for _, hit := range found {
+		seen[hit.ImdbID] = true
+	}
+
+	initials := wordInitials(terms)
+	filter := bson.M{
+		"deleted_at": nil,
+		"$or": bson.A{
+			bson.M{"title": bson.M{"$regex": initials, "$options": "i"}},
+			bson.M{"genre.genre_name": bson.M{"$regex": initials, "$options": "i"}},
+		},
+	}
+	projection := options.Find().
+		SetProjection(bson.M{"imdb_id": 1, "title": 1, "genre.genre_name": 1}).
+		SetLimit(fuzzyScanLimit)
+	cursor, err := movieCollection.Find(ctx, filter, projection)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var candidates []scoredMovie
+	for cursor.Next(ctx) {
+		var movie models.Movie
+		if err := cursor.Decode(&movie); err != nil {
+			return nil, err
+		}
+		if seen[movie.ImdbID] {
+			continue
+		}
+		if score := fuzzyScore(movie, terms); score >= fuzzyThreshold {
+			candidates = append(candidates, scoredMovie{Movie: movie, Score: score, Match: models.SearchMatchFuzzy})
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if int64(len(candidates)) > limit {
+		candidates = candidates[:limit]
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	// candidates only carry the projected fields, load the full documents
+	imdbIDs := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		imdbIDs = append(imdbIDs, candidate.ImdbID)
+	}
+	cursor, err = movieCollection.Find(ctx, bson.M{"imdb_id": bson.M{"$in": imdbIDs}}, options.Find().SetProjection(bson.M{"embedding": 0}))
+	if err != nil {
+		return nil, err
+	}
+	var movies []models.Movie
+	if err := cursor.All(ctx, &movies); err != nil {
+		return nil, err
+	}
+	byImdbID := make(map[string]models.Movie, len(movies))
+	for _, movie := range movies {
+		byImdbID[movie.ImdbID] = movie
+	}
+
+	hits := make([]scoredMovie, 0, len(candidates))
+	for _, candidate := range candidates {
+		if movie, ok := byImdbID[candidate.ImdbID]; ok {
+			hits = append(hits, scoredMovie{Movie: movie, Score: candidate.Score, Match: candidate.Match})
+		}
+	}
+	return hits, nil
+}
+
+// wordInitials returns a pattern matching a word that starts with the first
+// letter of any term. A typo rarely hits the first letter, so this narrows
+// the fuzzy candidates without losing many matches.
+func wordInitials(terms []string) string {
+	seen := map[string]bool{}
+	var initials []string
+	for _, term := range terms {
+		r, _ := utf8.DecodeRuneInString(term)
+		initial := regexp.QuoteMeta(string(r))
+		if !seen[initial] {
+			seen[initial] = true
+			initials = append(initials, initial)
+		}
+	}
+	return `\b(` + strings.Join(initials, "|") + `)`
+}
+
+// fuzzyScore is the average over query terms of the best match in the
+// title, with genre name matches weighted lower.
+func fuzzyScore(movie models.Movie, terms []string) float64 {
+	titleWords := Tokenize(movie.Title)
+
+	var genreWords []string
+	for _, genre := range movie.Genre {
+		genreWords = append(genreWords, Tokenize(genre.GenreName)...)
+	}
+
+	total := 0.0
+	for _, term := range terms {
+		total += max(bestSimilarity(term, titleWords), 0.6*bestSimilarity(term, genreWords))
+	}
+	return total / float64(len(terms))
+}
+
+func highlights(movie models.Movie, terms []string) map[string]string {
+	result := map[string]string{}
+
+	if title, ok := Highlight(movie.Title, terms, false); ok {
+		result["title"] = title
+	}
+	if review, ok := Highlight(movie.AdminReview, terms, true); ok {
+		result["admin_review"] = review
+	}
+
+	var genreNames []string
+	for _, genre := range movie.Genre {
+		genreNames = append(genreNames, genre.GenreName)
+	}
+	if genres, ok := Highlight(strings.Join(genreNames, ", "), terms, false); ok {
+		result["genre"] = genres
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}